@@ -2,116 +2,146 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"server/config"
+	"server/internal/auth"
+	"server/internal/bridge"
 	"server/internal/db"
 	"server/internal/nats"
-	"server/internal/poker"
 	"server/internal/server"
 	temporal "server/internal/workflow"
+	"syscall"
+)
 
-	"go.temporal.io/sdk/client"
-	"go.temporal.io/sdk/worker"
-	"go.temporal.io/sdk/workflow"
-	"gorm.io/gorm"
+const usage = "usage: pokerServer [api|worker|bridge|migrate|all]"
 
-	natsG "github.com/nats-io/nats.go"
-)
+// roles are the parts of the poker server that can run on their own. all
+// migrates and then runs every other role in one process.
+var roles = map[string]func(ctx context.Context, cfg *config.Config) error{
+	"api":     runAPI,
+	"worker":  runWorker,
+	"bridge":  runBridge,
+	"migrate": func(ctx context.Context, cfg *config.Config) error { return migrate(cfg) },
+	"all":     runAll,
+}
 
 func main() {
+	role := "all"
+	if len(os.Args) > 1 {
+		role = os.Args[1]
+	}
+	run, ok := roles[role]
+	if !ok {
+		log.Fatal(usage)
+	}
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	db.InitDB(&cfg.Database)
-	err = db.Migrate()
-	if err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg); err != nil {
+		log.Fatalf("%s stopped: %v", role, err)
+	}
+	log.Printf("%s stopped", role)
+}
+
+// migrate updates the database schema and the JetStream stream.
+func migrate(cfg *config.Config) error {
+	if _, err := db.InitDB(&cfg.Database); err != nil {
+		return err
+	}
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
 	natsConn, js, err := nats.Connect(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to NATS: %v", err)
+		return fmt.Errorf("failed to connect to NATS: %w", err)
 	}
 	defer natsConn.Close()
 
-	err = nats.ConfigureStream(js, &cfg.NATS.Stream)
-	if err != nil {
-		log.Fatalf("Failed to configure JetStream: %v", err)
+	if err := nats.ConfigureStream(js, &cfg.NATS.Stream); err != nil {
+		return fmt.Errorf("failed to configure JetStream: %w", err)
 	}
+	return nil
+}
 
-	temporalOptions := client.Options{
-		HostPort: cfg.Temporal.HostPort,
+func runAPI(ctx context.Context, cfg *config.Config) error {
+	if _, err := db.InitDB(&cfg.Database); err != nil {
+		return err
 	}
 
-	c, err := client.Dial(temporalOptions)
+	c, err := temporal.Dial(cfg)
 	if err != nil {
-		log.Fatalf("Failed to create Temporal client: %v", err)
+		return err
 	}
 	defer c.Close()
 
-	w := worker.New(c, "poker-task-queue", worker.Options{})
-	w.RegisterWorkflowWithOptions(temporal.TableWorkflow, workflow.RegisterOptions{Name: "TableWorkflow"})
-	w.RegisterActivity(temporal.DealCardsActivity)
-	w.RegisterActivity(temporal.DealPreFlop)
-	w.RegisterActivity(temporal.DealFlop)
-	w.RegisterActivity(temporal.DealTurn)
-	w.RegisterActivity(temporal.DealRiver)
+	return server.Run(ctx, cfg, c)
+}
 
-	err = w.Run(worker.InterruptCh())
+// runWorker polls the task queues of its shards until ctx is done, then
+// drains the workers.
+func runWorker(ctx context.Context, cfg *config.Config) error {
+	workers, err := temporal.StartWorker(cfg)
 	if err != nil {
-		log.Fatalf("Failed to start worker: %v", err)
+		return err
 	}
+	<-ctx.Done()
 
-	runTestWorkflows(c, db.GetDB(), js)
-
-	server.StartServer(cfg)
+	log.Println("Draining workers")
+	workers.Stop()
+	return nil
 }
 
-func runTestWorkflows(c client.Client, db *gorm.DB, js natsG.JetStreamContext) {
-	// Define dos tablas con diferentes jugadores
-	table1 := poker.Table{
-		CurrentBB:          "player1",
-		CurrentSB:          "player2",
-		CurrentTurn:        "player3",
-		NextTurn:           "player4",
-		CurrentStage:       "dealing",
-		FlopCards:          []poker.Card{},
-		TurnCard:           nil,
-		RiverCard:          nil,
-		TotalBetIndividual: make(map[string]int),
-	}
-
-	table2 := poker.Table{
-		CurrentBB:          "player6",
-		CurrentSB:          "player7",
-		CurrentTurn:        "player8",
-		NextTurn:           "player9",
-		CurrentStage:       "dealing",
-		FlopCards:          []poker.Card{},
-		TurnCard:           nil,
-		RiverCard:          nil,
-		TotalBetIndividual: make(map[string]int),
-	}
-
-	// Crea y ejecuta dos workflows
-	workflowID1 := "test-workflow-1"
-	workflowID2 := "test-workflow-2"
-
-	_, err := c.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{
-		ID:        workflowID1,
-		TaskQueue: "poker-task-queue",
-	}, "TableWorkflow", table1, db, js)
+func runBridge(ctx context.Context, cfg *config.Config) error {
+	natsConn, js, err := nats.Connect(cfg)
 	if err != nil {
-		log.Fatalf("Failed to start workflow 1: %v", err)
+		return fmt.Errorf("failed to connect to NATS: %w", err)
 	}
+	defer natsConn.Close()
 
-	_, err = c.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{
-		ID:        workflowID2,
-		TaskQueue: "poker-task-queue",
-	}, "TableWorkflow", table2, db, js)
+	c, err := temporal.Dial(cfg)
 	if err != nil {
-		log.Fatalf("Failed to start workflow 2: %v", err)
+		return err
+	}
+	defer c.Close()
+
+	return bridge.Run(ctx, js, c, auth.NewVerifier(cfg.Auth.Secret))
+}
+
+// runAll migrates, then runs the API, the worker and the bridge until ctx is
+// done or one of them fails, and stops the others.
+func runAll(ctx context.Context, cfg *config.Config) error {
+	if err := migrate(cfg); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	parts := []func(context.Context, *config.Config) error{runAPI, runWorker, runBridge}
+	errs := make(chan error, len(parts))
+	for _, run := range parts {
+		go func() {
+			err := run(ctx, cfg)
+			cancel()
+			errs <- err
+		}()
+	}
+
+	var first error
+	for range parts {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+		}
 	}
+	return first
 }
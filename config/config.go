@@ -1,6 +1,10 @@
 package config
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/spf13/viper"
 )
 
@@ -29,14 +33,60 @@ type ServerConfig struct {
 }
 
 type Config struct {
-	Database DatabaseConfig `mapstructure:"database"`
-	NATS     NATSConfig     `mapstructure:"nats"`
-	Server   ServerConfig   `mapstructure:"server"`
-	Temporal TemporalConfig `mapstructure:"temporal"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	NATS        NATSConfig        `mapstructure:"nats"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Temporal    TemporalConfig    `mapstructure:"temporal"`
+	WaitingList WaitingListConfig `mapstructure:"waitinglist"`
+	Codec       CodecConfig       `mapstructure:"codec"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+
+	// DevMode, set with POKER_DEV_MODE=true, lets the server run without
+	// codec keys for local development.
+	DevMode bool `mapstructure:"-"`
 }
 
+// TemporalConfig names the task queue shards tables run on. New tables are
+// spread over Shards; with none they stay on the default queue. A worker polls
+// WorkerShards, where "default" is the queue tournaments, waiting lists and
+// schedules run on; with none it polls the default queue and every shard.
 type TemporalConfig struct {
-	HostPort string `mapstructure:"hostport"`
+	HostPort     string   `mapstructure:"hostport"`
+	Shards       []string `mapstructure:"shards"`
+	WorkerShards []string `mapstructure:"workershards"`
+}
+
+// AuthConfig verifies the bearer tokens of API callers, HS256 JWTs signed by
+// the login service. Secret is read from POKER_AUTH_SECRET or from the file
+// named by POKER_AUTH_SECRET_FILE, never from the config file.
+type AuthConfig struct {
+	Secret string `mapstructure:"-" json:"-"`
+}
+
+type WaitingListConfig struct {
+	ReservationSeconds int `mapstructure:"reservationseconds"`
+}
+
+// CodecConfig holds the keys that encrypt Temporal payloads. They are read
+// from POKER_CODEC_KEYS, or the file named by POKER_CODEC_KEYS_FILE, as a
+// comma separated list of id:base64-key. The first key, ActiveKey, encrypts
+// new payloads; every key can still decrypt, so a key is rotated by putting a
+// new one first and removing the old one once no open workflow uses it.
+// Without keys payloads are stored in plaintext, which is only allowed in dev
+// mode.
+//
+// The codec server decodes payloads for the Temporal UI. It requires a staff
+// token and answers browsers from AllowedOrigins. Keys are never serialized,
+// so they stay out of workflow history.
+type CodecConfig struct {
+	ActiveKey      string     `mapstructure:"-"`
+	Keys           []CodecKey `mapstructure:"-" json:"-"`
+	AllowedOrigins []string   `mapstructure:"allowedorigins"`
+}
+
+type CodecKey struct {
+	ID  string `mapstructure:"id"`
+	Key string `mapstructure:"key"` // base64 of 32 bytes, AES-256
 }
 
 func LoadConfig() (*Config, error) {
@@ -55,9 +105,61 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	secret, err := readSecret("POKER_AUTH_SECRET")
+	if err != nil {
+		return nil, err
+	}
+	cfg.Auth.Secret = secret
+
+	keys, err := readSecret("POKER_CODEC_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Codec.Keys, err = parseCodecKeys(keys); err != nil {
+		return nil, err
+	}
+	if len(cfg.Codec.Keys) > 0 {
+		cfg.Codec.ActiveKey = cfg.Codec.Keys[0].ID
+	}
+	cfg.DevMode = os.Getenv("POKER_DEV_MODE") == "true"
+
 	return &cfg, nil
 }
 
+// parseCodecKeys reads a comma separated list of id:base64-key.
+func parseCodecKeys(value string) ([]CodecKey, error) {
+	var keys []CodecKey
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, key, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || key == "" {
+			return nil, fmt.Errorf("codec keys must be id:base64-key, got %q", id)
+		}
+		keys = append(keys, CodecKey{ID: id, Key: key})
+	}
+	return keys, nil
+}
+
+// readSecret returns the value of the environment variable name, or the
+// contents of the file named by name_FILE.
+func readSecret(name string) (string, error) {
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s_FILE: %w", name, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
 func init() {
 	viper.AutomaticEnv()
 }
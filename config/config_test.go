@@ -1,6 +1,7 @@
 package config
 
 import (
+	"os"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -20,3 +21,27 @@ func TestLoadConfig(t *testing.T) {
 	assert.NotNil(t, cfg, "Config should not be nil")
 	assert.NotNil(t, cfg.Database, "Database config should not be nil")
 }
+
+func TestLoadConfigSecrets(t *testing.T) {
+	viper.SetConfigFile("./config.yaml")
+	keyFile := t.TempDir() + "/keys"
+	if err := os.WriteFile(keyFile, []byte("k2:bmV3,k1:b2xk\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("POKER_AUTH_SECRET", "secret")
+	t.Setenv("POKER_CODEC_KEYS_FILE", keyFile)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	assert.Equal(t, "secret", cfg.Auth.Secret)
+	assert.Equal(t, "k2", cfg.Codec.ActiveKey, "The first key should be active")
+	assert.Equal(t, []CodecKey{{ID: "k2", Key: "bmV3"}, {ID: "k1", Key: "b2xk"}}, cfg.Codec.Keys)
+	assert.False(t, cfg.DevMode)
+
+	t.Setenv("POKER_CODEC_KEYS", "no-separator")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
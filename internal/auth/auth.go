@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RoleStaff is the role of support staff and tournament directors.
+const RoleStaff = "staff"
+
+var (
+	// ErrNoToken is returned for requests without a bearer token.
+	ErrNoToken = errors.New("missing bearer token")
+	// ErrInvalidToken is returned for tokens that are malformed, expired or
+	// not signed with the configured secret.
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Identity is the caller named by a token.
+type Identity struct {
+	PlayerID string
+	Role     string
+}
+
+// Staff reports whether the caller is support staff.
+func (id Identity) Staff() bool {
+	return id.Role == RoleStaff
+}
+
+type claims struct {
+	Subject   string `json:"sub"`
+	Role      string `json:"role,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Verifier checks the HS256 JWTs issued by the login service. The subject of
+// a token is the player ID and its role claim is RoleStaff for staff.
+type Verifier struct {
+	secret []byte
+	now    func() time.Time
+}
+
+func NewVerifier(secret string) *Verifier {
+	return &Verifier{secret: []byte(secret), now: time.Now}
+}
+
+// Enabled reports whether a secret is configured. Without one every caller
+// is anonymous.
+func (v *Verifier) Enabled() bool {
+	return len(v.secret) > 0
+}
+
+// FromRequest returns the identity of the bearer token of r.
+func (v *Verifier) FromRequest(r *http.Request) (Identity, error) {
+	return v.FromAuthorization(r.Header.Get("Authorization"))
+}
+
+// FromAuthorization returns the identity of an Authorization header value.
+func (v *Verifier) FromAuthorization(header string) (Identity, error) {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if header == "" || !ok {
+		return Identity{}, ErrNoToken
+	}
+	return v.Verify(token)
+}
+
+// Verify checks the signature and expiry of token.
+func (v *Verifier) Verify(token string) (Identity, error) {
+	if !v.Enabled() {
+		return Identity{}, ErrInvalidToken
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return Identity{}, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, v.sign(parts[0]+"."+parts[1])) {
+		return Identity{}, ErrInvalidToken
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil || c.Subject == "" {
+		return Identity{}, ErrInvalidToken
+	}
+	if c.ExpiresAt == 0 || !v.now().Before(time.Unix(c.ExpiresAt, 0)) {
+		return Identity{}, fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	return Identity{PlayerID: c.Subject, Role: c.Role}, nil
+}
+
+// Sign issues a token for id that expires after ttl.
+func (v *Verifier) Sign(id Identity, ttl time.Duration) (string, error) {
+	payload, err := json.Marshal(claims{Subject: id.PlayerID, Role: id.Role, ExpiresAt: v.now().Add(ttl).Unix()})
+	if err != nil {
+		return "", err
+	}
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(v.sign(unsigned)), nil
+}
+
+func (v *Verifier) sign(unsigned string) []byte {
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
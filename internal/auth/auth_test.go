@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyRoundTrip(t *testing.T) {
+	v := NewVerifier("secret")
+	token, err := v.Sign(Identity{PlayerID: "player1", Role: RoleStaff}, time.Hour)
+	require.NoError(t, err)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	id, err := v.FromRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, "player1", id.PlayerID)
+	assert.True(t, id.Staff())
+}
+
+func TestVerifyRejectsBadTokens(t *testing.T) {
+	v := NewVerifier("secret")
+	token, err := NewVerifier("other").Sign(Identity{PlayerID: "player1"}, time.Hour)
+	require.NoError(t, err)
+	_, err = v.Verify(token)
+	assert.ErrorIs(t, err, ErrInvalidToken, "Token signed with another secret")
+
+	expired, err := v.Sign(Identity{PlayerID: "player1"}, -time.Minute)
+	require.NoError(t, err)
+	_, err = v.Verify(expired)
+	assert.ErrorIs(t, err, ErrInvalidToken, "Expired token")
+
+	_, err = v.FromRequest(httptest.NewRequest("GET", "/", nil))
+	assert.ErrorIs(t, err, ErrNoToken)
+
+	_, err = NewVerifier("").Verify(token)
+	assert.ErrorIs(t, err, ErrInvalidToken, "No secret configured")
+}
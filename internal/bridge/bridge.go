@@ -0,0 +1,130 @@
+// Package bridge forwards the messages clients publish on NATS to the
+// Temporal workflows they are meant for.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"server/internal/auth"
+	"server/internal/poker"
+	temporal "server/internal/workflow"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+const (
+	// ClientSubjects covers every message sent by clients.
+	ClientSubjects = "pokerClient.tournament.>"
+
+	consumerName = "pokerBridge"
+)
+
+// Signal is a client message translated into a workflow signal on behalf of
+// PlayerID.
+type Signal struct {
+	WorkflowID string
+	Name       string
+	Arg        interface{}
+	PlayerID   string
+}
+
+// Route translates a client message. Player actions are published on
+// poker.ActionSubject and deal replies on poker.DealReplySubject; the player
+// is always taken from the subject, which Authorize checks against the
+// sender's token.
+func Route(subject string, data []byte) (Signal, error) {
+	tokens := strings.Split(subject, ".")
+	switch {
+	case len(tokens) == 4:
+		var action poker.PlayerAction
+		if err := json.Unmarshal(data, &action); err != nil {
+			return Signal{}, fmt.Errorf("invalid action on %s: %w", subject, err)
+		}
+		action.PlayerID = tokens[3]
+		return Signal{WorkflowID: temporal.TableWorkflowID(tokens[2]), Name: temporal.PlayerActionSignal, Arg: action, PlayerID: tokens[3]}, nil
+	case len(tokens) == 5 && tokens[3] == "deal":
+		var reply temporal.DealReply
+		if err := json.Unmarshal(data, &reply); err != nil {
+			return Signal{}, fmt.Errorf("invalid deal reply on %s: %w", subject, err)
+		}
+		reply.PlayerID = tokens[4]
+		return Signal{WorkflowID: temporal.DealWorkflowID(tokens[2]), Name: temporal.DealReplySignal, Arg: reply, PlayerID: tokens[4]}, nil
+	}
+	return Signal{}, fmt.Errorf("unknown client subject %s", subject)
+}
+
+// Authorize checks that a message sent for playerID carries that player's
+// token in its Authorization header. Without an auth secret every client is
+// trusted to publish only on its own subjects.
+func Authorize(v *auth.Verifier, header nats.Header, playerID string) error {
+	if !v.Enabled() {
+		return nil
+	}
+	id, err := v.FromAuthorization(header.Get("Authorization"))
+	if err != nil {
+		return err
+	}
+	if id.PlayerID != playerID {
+		return fmt.Errorf("token of player %s cannot act for player %s", id.PlayerID, playerID)
+	}
+	return nil
+}
+
+// Run forwards client messages until ctx is done. Messages for workflows that
+// are not running are dropped; other failures are redelivered.
+func Run(ctx context.Context, js nats.JetStreamContext, c client.Client, v *auth.Verifier) error {
+	if !v.Enabled() {
+		log.Println("No auth secret configured, client messages are not authenticated")
+	}
+
+	msgChan := make(chan *nats.Msg, 256)
+	sub, err := js.ChanSubscribe(ClientSubjects, msgChan, nats.Durable(consumerName), nats.AckExplicit())
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", ClientSubjects, err)
+	}
+	defer func() {
+		if err := sub.Unsubscribe(); err != nil {
+			log.Printf("Failed to unsubscribe from %s: %v", ClientSubjects, err)
+		}
+	}()
+
+	for {
+		select {
+		case msg := <-msgChan:
+			forward(ctx, c, v, msg)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func forward(ctx context.Context, c client.Client, v *auth.Verifier, msg *nats.Msg) {
+	signal, err := Route(msg.Subject, msg.Data)
+	if err == nil {
+		err = Authorize(v, msg.Header, signal.PlayerID)
+	}
+	if err != nil {
+		log.Printf("Dropping client message: %v", err)
+		_ = msg.Term()
+		return
+	}
+
+	err = c.SignalWorkflow(ctx, signal.WorkflowID, "", signal.Name, signal.Arg)
+	var notFound *serviceerror.NotFound
+	switch {
+	case err == nil:
+		_ = msg.Ack()
+	case errors.As(err, &notFound):
+		log.Printf("Dropping %s for %s: workflow is not running", signal.Name, signal.WorkflowID)
+		_ = msg.Ack()
+	default:
+		log.Printf("Failed to signal %s with %s: %v", signal.WorkflowID, signal.Name, err)
+		_ = msg.Nak()
+	}
+}
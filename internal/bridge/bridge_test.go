@@ -0,0 +1,50 @@
+package bridge
+
+import (
+	"server/internal/auth"
+	"server/internal/poker"
+	temporal "server/internal/workflow"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutePlayerAction(t *testing.T) {
+	signal, err := Route(poker.ActionSubject("7-1", "42"), []byte(`{"PlayerID":"99","Action":"raise","Amount":300}`))
+	assert.NoError(t, err)
+	assert.Equal(t, temporal.TableWorkflowID("7-1"), signal.WorkflowID)
+	assert.Equal(t, temporal.PlayerActionSignal, signal.Name)
+	assert.Equal(t, poker.PlayerAction{PlayerID: "42", Action: "raise", Amount: 300}, signal.Arg, "Player should come from the subject")
+	assert.Equal(t, "42", signal.PlayerID)
+}
+
+func TestRouteDealReply(t *testing.T) {
+	signal, err := Route(poker.DealReplySubject("7", "42"), []byte(`{"Accept":true}`))
+	assert.NoError(t, err)
+	assert.Equal(t, temporal.DealWorkflowID("7"), signal.WorkflowID)
+	assert.Equal(t, temporal.DealReplySignal, signal.Name)
+	assert.Equal(t, temporal.DealReply{PlayerID: "42", Accept: true}, signal.Arg)
+}
+
+func TestRouteRejectsUnknownSubjects(t *testing.T) {
+	_, err := Route("pokerClient.tournament.7", []byte(`{}`))
+	assert.Error(t, err)
+	_, err = Route(poker.ActionSubject("7-1", "42"), []byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestAuthorizeChecksSender(t *testing.T) {
+	v := auth.NewVerifier("secret")
+	token, err := v.Sign(auth.Identity{PlayerID: "42"}, time.Minute)
+	require.NoError(t, err)
+	header := nats.Header{}
+	header.Set("Authorization", "Bearer "+token)
+
+	assert.NoError(t, Authorize(v, header, "42"))
+	assert.Error(t, Authorize(v, header, "43"), "A player should not act for another")
+	assert.ErrorIs(t, Authorize(v, nats.Header{}, "42"), auth.ErrNoToken)
+	assert.NoError(t, Authorize(auth.NewVerifier(""), nats.Header{}, "42"), "Without a secret clients are trusted")
+}
@@ -0,0 +1,130 @@
+package codec
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"server/config"
+
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/converter"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// MetadataEncoding marks payloads encrypted by Codec.
+	MetadataEncoding = "binary/encrypted"
+	// MetadataKeyID names the key that encrypted a payload.
+	MetadataKeyID = "encryption-key-id"
+)
+
+// Codec encrypts Temporal payloads with AES-256-GCM. New payloads use the
+// active key; payloads encrypted with any configured key can be decrypted, so
+// keys can be rotated without losing the history of open workflows.
+type Codec struct {
+	activeKey string
+	keys      map[string]cipher.AEAD
+}
+
+// New builds a Codec from the configured keys.
+func New(cfg config.CodecConfig) (*Codec, error) {
+	c := &Codec{activeKey: cfg.ActiveKey, keys: make(map[string]cipher.AEAD, len(cfg.Keys))}
+	for _, key := range cfg.Keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("codec key without id")
+		}
+		if _, ok := c.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate codec key %q", key.ID)
+		}
+		raw, err := base64.StdEncoding.DecodeString(key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("codec key %q is not base64: %w", key.ID, err)
+		}
+		if len(raw) != 32 {
+			return nil, fmt.Errorf("codec key %q must be 32 bytes, got %d", key.ID, len(raw))
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.keys[key.ID] = aead
+	}
+	if _, ok := c.keys[c.activeKey]; !ok {
+		return nil, fmt.Errorf("active codec key %q is not configured", c.activeKey)
+	}
+	return c, nil
+}
+
+// Encode implements converter.PayloadCodec.
+func (c *Codec) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	aead := c.keys[c.activeKey]
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, p := range payloads {
+		plain, err := proto.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		result[i] = &commonpb.Payload{
+			Metadata: map[string][]byte{
+				converter.MetadataEncoding: []byte(MetadataEncoding),
+				MetadataKeyID:              []byte(c.activeKey),
+			},
+			Data: aead.Seal(nonce, nonce, plain, []byte(c.activeKey)),
+		}
+	}
+	return result, nil
+}
+
+// Decode implements converter.PayloadCodec. Payloads that were not encrypted
+// are returned as they are.
+func (c *Codec) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, p := range payloads {
+		if string(p.Metadata[converter.MetadataEncoding]) != MetadataEncoding {
+			result[i] = p
+			continue
+		}
+		keyID := string(p.Metadata[MetadataKeyID])
+		aead, ok := c.keys[keyID]
+		if !ok {
+			return nil, fmt.Errorf("unknown codec key %q", keyID)
+		}
+		if len(p.Data) < aead.NonceSize() {
+			return nil, fmt.Errorf("encrypted payload too short")
+		}
+		nonce, sealed := p.Data[:aead.NonceSize()], p.Data[aead.NonceSize():]
+		plain, err := aead.Open(nil, nonce, sealed, []byte(keyID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt payload with key %q: %w", keyID, err)
+		}
+		decoded := &commonpb.Payload{}
+		if err := proto.Unmarshal(plain, decoded); err != nil {
+			return nil, err
+		}
+		result[i] = decoded
+	}
+	return result, nil
+}
+
+// DataConverter returns the data converter for the configured keys. Without
+// keys payloads are left in plaintext.
+func DataConverter(cfg config.CodecConfig) (converter.DataConverter, error) {
+	if len(cfg.Keys) == 0 {
+		return converter.GetDefaultDataConverter(), nil
+	}
+	c, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return converter.NewCodecDataConverter(converter.GetDefaultDataConverter(), c), nil
+}
@@ -0,0 +1,99 @@
+package codec
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/converter"
+)
+
+const (
+	oldKey = "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="
+	newKey = "YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXphYmNkZWY="
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	c, err := New(config.CodecConfig{ActiveKey: "k1", Keys: []config.CodecKey{{ID: "k1", Key: oldKey}}})
+	require.NoError(t, err)
+
+	payload, err := converter.GetDefaultDataConverter().ToPayload(map[string]int{"pot": 120})
+	require.NoError(t, err)
+
+	encoded, err := c.Encode([]*commonpb.Payload{payload})
+	require.NoError(t, err)
+	assert.Equal(t, MetadataEncoding, string(encoded[0].Metadata[converter.MetadataEncoding]))
+	assert.Equal(t, "k1", string(encoded[0].Metadata[MetadataKeyID]))
+	assert.NotContains(t, string(encoded[0].Data), "pot")
+
+	decoded, err := c.Decode(encoded)
+	require.NoError(t, err)
+	var out map[string]int
+	require.NoError(t, converter.GetDefaultDataConverter().FromPayload(decoded[0], &out))
+	assert.Equal(t, 120, out["pot"])
+}
+
+func TestCodecKeyRotation(t *testing.T) {
+	before, err := New(config.CodecConfig{ActiveKey: "k1", Keys: []config.CodecKey{{ID: "k1", Key: oldKey}}})
+	require.NoError(t, err)
+	after, err := New(config.CodecConfig{ActiveKey: "k2", Keys: []config.CodecKey{{ID: "k1", Key: oldKey}, {ID: "k2", Key: newKey}}})
+	require.NoError(t, err)
+
+	payload, err := converter.GetDefaultDataConverter().ToPayload("hand")
+	require.NoError(t, err)
+	old, err := before.Encode([]*commonpb.Payload{payload})
+	require.NoError(t, err)
+
+	decoded, err := after.Decode(old)
+	require.NoError(t, err)
+	assert.Equal(t, payload.Data, decoded[0].Data)
+
+	fresh, err := after.Encode([]*commonpb.Payload{payload})
+	require.NoError(t, err)
+	assert.Equal(t, "k2", string(fresh[0].Metadata[MetadataKeyID]))
+	_, err = before.Decode(fresh)
+	assert.ErrorContains(t, err, `unknown codec key "k2"`)
+}
+
+func TestCodecPassesPlainPayloads(t *testing.T) {
+	c, err := New(config.CodecConfig{ActiveKey: "k1", Keys: []config.CodecKey{{ID: "k1", Key: oldKey}}})
+	require.NoError(t, err)
+
+	payload, err := converter.GetDefaultDataConverter().ToPayload("plain")
+	require.NoError(t, err)
+	decoded, err := c.Decode([]*commonpb.Payload{payload})
+	require.NoError(t, err)
+	assert.Same(t, payload, decoded[0])
+}
+
+func TestNewRejectsInvalidKeys(t *testing.T) {
+	_, err := New(config.CodecConfig{ActiveKey: "k1", Keys: []config.CodecKey{{ID: "k1", Key: "c2hvcnQ="}}})
+	assert.ErrorContains(t, err, "must be 32 bytes")
+
+	_, err = New(config.CodecConfig{ActiveKey: "k2", Keys: []config.CodecKey{{ID: "k1", Key: oldKey}}})
+	assert.ErrorContains(t, err, "not configured")
+}
+
+func TestCORSAnswersPreflight(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("Preflight should not reach the codec")
+	})
+	handler := CORS([]string{"http://localhost:8080"}, next)
+
+	req := httptest.NewRequest(http.MethodOptions, "/codec/decode", nil)
+	req.Header.Set("Origin", "http://localhost:8080")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "http://localhost:8080", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	req = httptest.NewRequest(http.MethodOptions, "/codec/decode", nil)
+	req.Header.Set("Origin", "http://evil.example")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
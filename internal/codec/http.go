@@ -0,0 +1,34 @@
+package codec
+
+import (
+	"net/http"
+	"slices"
+
+	"go.temporal.io/sdk/converter"
+)
+
+// NewHTTPHandler serves the codec server the Temporal UI calls to show
+// decrypted payloads. It does no authorisation of its own.
+func NewHTTPHandler(c *Codec) http.Handler {
+	return converter.NewPayloadCodecHTTPHandler(c)
+}
+
+// CORS lets browsers on origins call next, and answers their preflight
+// requests itself.
+func CORS(origins []string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && slices.Contains(origins, origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Namespace, Authorization")
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Add("Vary", "Origin")
+		}
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"server/internal/poker"
 	"time"
 )
 
@@ -17,6 +18,7 @@ type Wallet struct {
 	UserID        uint      `gorm:"not null;unique"`
 	User          User      `gorm:"foreignKey:UserID"`
 	WalletAddress string    `gorm:"size:100;not null;unique"`
+	Balance       int       `gorm:"not null;default:0"`
 	CreatedAt     time.Time `gorm:"autoCreateTime"`
 	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
 	DeletedAt     time.Time `gorm:"index"`
@@ -30,14 +32,18 @@ type Tournament struct {
 	StartDate             time.Time `gorm:"not null"`
 	EndDate               time.Time
 	Prize                 string
-	Configuration         string
-	Ongoing               bool      `gorm:"default:false"`
-	MinPlayers            int       `gorm:"not null"`
-	MaxPlayers            int       `gorm:"not null"`
-	TurnSeconds           int       `gorm:"not null"`
-	CreatedAt             time.Time `gorm:"autoCreateTime"`
-	UpdatedAt             time.Time `gorm:"autoUpdateTime"`
-	DeletedAt             time.Time `gorm:"index"`
+	Configuration         poker.TournamentConfig `gorm:"type:text;serializer:json"`
+	Ongoing               bool                   `gorm:"default:false"`
+	Cancelled             bool                   `gorm:"default:false"`
+	MinPlayers            int                    `gorm:"not null"`
+	MaxPlayers            int                    `gorm:"not null"`
+	TurnSeconds           int                    `gorm:"not null"`
+	TableSize             int                    `gorm:"not null;default:9"`
+	PrizePool             int                    `gorm:"not null;default:0"`
+	Overlay               int                    `gorm:"not null;default:0"`
+	CreatedAt             time.Time              `gorm:"autoCreateTime"`
+	UpdatedAt             time.Time              `gorm:"autoUpdateTime"`
+	DeletedAt             time.Time              `gorm:"index"`
 }
 
 type TournamentChip struct {
@@ -58,6 +64,11 @@ type TournamentRegistration struct {
 	Tournament   Tournament `gorm:"foreignKey:TournamentID"`
 	WalletID     uint       `gorm:"not null"`
 	Wallet       Wallet     `gorm:"foreignKey:WalletID"`
+	Entry        int        `gorm:"not null;default:1"`
+	Chips        int        `gorm:"not null;default:0"`
+	Rebuys       int        `gorm:"not null;default:0"`
+	AddOn        bool       `gorm:"not null;default:false"`
+	BountiesWon  int        `gorm:"not null;default:0"`
 	CreatedAt    time.Time  `gorm:"autoCreateTime"`
 	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
 	DeletedAt    time.Time  `gorm:"index"`
@@ -79,6 +90,7 @@ type TablePlayer struct {
 	Table     Table     `gorm:"foreignKey:TableID"`
 	WalletID  uint      `gorm:"not null"`
 	Wallet    Wallet    `gorm:"foreignKey:WalletID"`
+	Seat      int       `gorm:"not null;default:0"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime"`
 	DeletedAt time.Time `gorm:"index"`
@@ -90,12 +102,37 @@ type Ranking struct {
 	Tournament   Tournament `gorm:"foreignKey:TournamentID"`
 	WalletID     uint       `gorm:"not null"`
 	Wallet       Wallet     `gorm:"foreignKey:WalletID"`
+	Entry        int        `gorm:"not null;default:1"`
 	Position     int        `gorm:"not null"`
+	Prize        int        `gorm:"not null;default:0"`
+	Bounties     int        `gorm:"not null;default:0"`
 	CreatedAt    time.Time  `gorm:"autoCreateTime"`
 	UpdatedAt    time.Time  `gorm:"autoUpdateTime"`
 	DeletedAt    time.Time  `gorm:"index"`
 }
 
+type RakeRecord struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement"`
+	TableID    string    `gorm:"size:100;not null;uniqueIndex:idx_rake_pot"`
+	HandNumber int       `gorm:"not null;uniqueIndex:idx_rake_pot"`
+	PotIndex   int       `gorm:"not null;uniqueIndex:idx_rake_pot"`
+	PotAmount  int       `gorm:"not null"`
+	Rake       int       `gorm:"not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+	DeletedAt  time.Time `gorm:"index"`
+}
+
+// WalletOperation records a debit or credit by its idempotency key, so the
+// same operation is never applied twice.
+type WalletOperation struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	Key       string    `gorm:"size:255;not null;uniqueIndex"`
+	WalletID  uint      `gorm:"not null;index"`
+	Amount    int       `gorm:"not null"` // negative for debits
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
 type RecordLog struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement"`
 	TableName string    `gorm:"size:100;not null"`
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"server/internal/db/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientBalance = errors.New("insufficient wallet balance")
+	ErrWalletNotFound      = errors.New("wallet not found")
+)
+
+// DebitWallet takes amount from the wallet balance, failing if the balance
+// is not enough to cover it.
+func DebitWallet(tx *gorm.DB, walletID uint, amount int) error {
+	result := tx.Model(&models.Wallet{}).
+		Where("id = ? AND balance >= ?", walletID, amount).
+		Update("balance", gorm.Expr("balance - ?", amount))
+	if result.Error != nil {
+		return fmt.Errorf("failed to debit wallet %d: %w", walletID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("wallet %d cannot cover %d: %w", walletID, amount, ErrInsufficientBalance)
+	}
+	return nil
+}
+
+func CreditWallet(tx *gorm.DB, walletID uint, amount int) error {
+	result := tx.Model(&models.Wallet{}).
+		Where("id = ?", walletID).
+		Update("balance", gorm.Expr("balance + ?", amount))
+	if result.Error != nil {
+		return fmt.Errorf("failed to credit wallet %d: %w", walletID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("wallet %d: %w", walletID, ErrWalletNotFound)
+	}
+	return nil
+}
+
+// ApplyWalletOperation debits (negative amount) or credits a wallet once per
+// key. Applying a key again is a no-op.
+func ApplyWalletOperation(conn *gorm.DB, key string, walletID uint, amount int) error {
+	return conn.Transaction(func(tx *gorm.DB) error {
+		operation := models.WalletOperation{Key: key, WalletID: walletID, Amount: amount}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&operation)
+		if result.Error != nil {
+			return fmt.Errorf("failed to record wallet operation %s: %w", key, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if amount < 0 {
+			return DebitWallet(tx, walletID, -amount)
+		}
+		return CreditWallet(tx, walletID, amount)
+	})
+}
@@ -0,0 +1,164 @@
+package poker
+
+import "sort"
+
+// SeatMove moves a tournament player to another table between hands.
+type SeatMove struct {
+	PlayerID      string
+	WalletID      uint
+	FromTable     string
+	ToTable       string
+	ToTableNumber int
+	Seat          int
+}
+
+// PlanBalance breaks the tables the field no longer needs and moves players
+// until table sizes differ by at most one. Moves are applied to tables right
+// away; only players on tables for which movable returns true are moved, so
+// tables in the middle of a hand keep their players.
+func PlanBalance(tables []*Table, movable func(*Table) bool) []SeatMove {
+	var moves []SeatMove
+
+	active := func() []*Table {
+		var result []*Table
+		for _, table := range tables {
+			if table.PlayerCount() > 0 {
+				result = append(result, table)
+			}
+		}
+		return result
+	}
+
+	// Break the short-handed tables first, highest number first on ties.
+	for {
+		open := active()
+		if len(open) <= 1 || len(open) <= tablesNeeded(open) {
+			break
+		}
+		sort.SliceStable(open, func(i, j int) bool {
+			if open[i].PlayerCount() != open[j].PlayerCount() {
+				return open[i].PlayerCount() < open[j].PlayerCount()
+			}
+			return open[i].Number > open[j].Number
+		})
+
+		broken := false
+		for _, table := range open {
+			if !movable(table) || !fitsElsewhere(open, table) {
+				continue
+			}
+			for _, player := range table.SeatedPlayers() {
+				moves = append(moves, movePlayer(table, smallestTable(open, table), player.ID))
+			}
+			broken = true
+			break
+		}
+		if !broken {
+			break
+		}
+	}
+
+	// Then even out the remaining tables one player at a time.
+	for {
+		open := active()
+		var from *Table
+		for _, table := range open {
+			if movable(table) && (from == nil || table.PlayerCount() > from.PlayerCount()) {
+				from = table
+			}
+		}
+		if from == nil {
+			break
+		}
+		to := smallestTable(open, from)
+		if to == nil || from.PlayerCount()-to.PlayerCount() <= 1 {
+			break
+		}
+		moves = append(moves, movePlayer(from, to, from.nextToPostBB()))
+	}
+
+	return moves
+}
+
+func tablesNeeded(tables []*Table) int {
+	players := 0
+	for _, table := range tables {
+		players += table.PlayerCount()
+	}
+	size := len(tables[0].Seats)
+	return (players + size - 1) / size
+}
+
+// fitsElsewhere reports whether the players of table fit in the empty seats of
+// the other tables.
+func fitsElsewhere(tables []*Table, table *Table) bool {
+	free := 0
+	for _, other := range tables {
+		if other != table {
+			free += len(other.OpenSeats())
+		}
+	}
+	return free >= table.PlayerCount()
+}
+
+// smallestTable returns the table with the fewest players and an open seat,
+// other than except.
+func smallestTable(tables []*Table, except *Table) *Table {
+	var smallest *Table
+	for _, table := range tables {
+		if table == except || len(table.OpenSeats()) == 0 {
+			continue
+		}
+		if smallest == nil || table.PlayerCount() < smallest.PlayerCount() {
+			smallest = table
+		}
+	}
+	return smallest
+}
+
+// nextToPostBB returns the player who would post the big blind next hand, the
+// one a fair move takes away.
+func (table *Table) nextToPostBB() string {
+	start := table.SeatIndexOf(table.CurrentBB)
+	if start == -1 {
+		start = len(table.Seats) - 1
+	}
+	index := table.nextSeatIndex(start, func(player *Player) bool { return true })
+	return table.Seats[index].Player.ID
+}
+
+// equivalentSeat returns the open seat right after the big blind, where a
+// moved player posts the big blind next just as they would have at their old
+// table.
+func (table *Table) equivalentSeat() int {
+	start := table.SeatIndexOf(table.CurrentBB)
+	if start == -1 {
+		return table.OpenSeats()[0]
+	}
+	for i := 1; i <= len(table.Seats); i++ {
+		seat := table.Seats[(start+i)%len(table.Seats)]
+		if seat.IsEmpty() && seat.ReservedFor == "" {
+			return seat.Number
+		}
+	}
+	return table.OpenSeats()[0]
+}
+
+func movePlayer(from *Table, to *Table, playerID string) SeatMove {
+	player, _ := from.StandUp(playerID)
+	player.IsBB = false
+	player.IsSB = false
+	player.IsTurn = false
+
+	seat := to.equivalentSeat()
+	_ = to.SitPlayer(seat, player)
+
+	return SeatMove{
+		PlayerID:      player.ID,
+		WalletID:      player.WalletID,
+		FromTable:     from.ID,
+		ToTable:       to.ID,
+		ToTableNumber: to.Number,
+		Seat:          seat,
+	}
+}
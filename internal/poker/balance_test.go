@@ -0,0 +1,56 @@
+package poker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newBalanceTables(t *testing.T, sizes ...int) []*Table {
+	var tables []*Table
+	for i, size := range sizes {
+		table, err := NewTable(TournamentTableID("1", i+1), 9)
+		assert.NoError(t, err)
+		table.Number = i + 1
+		for j := 0; j < size; j++ {
+			_, err := table.SitPlayerAnywhere(Player{ID: fmt.Sprintf("t%d-p%d", i+1, j+1), Chips: 1000})
+			assert.NoError(t, err)
+		}
+		tables = append(tables, &table)
+	}
+	return tables
+}
+
+func allMovable(*Table) bool { return true }
+
+func TestPlanBalanceEvensTables(t *testing.T) {
+	tables := newBalanceTables(t, 9, 6)
+	tables[0].CurrentBB = "t1-p3"
+	tables[1].CurrentBB = "t2-p2"
+
+	moves := PlanBalance(tables, allMovable)
+	assert.Len(t, moves, 1)
+	assert.Equal(t, "t1-p4", moves[0].PlayerID)
+	assert.Equal(t, "1-2", moves[0].ToTable)
+	assert.Equal(t, 7, moves[0].Seat)
+	assert.Equal(t, 8, tables[0].PlayerCount())
+	assert.Equal(t, 7, tables[1].PlayerCount())
+}
+
+func TestPlanBalanceBreaksTable(t *testing.T) {
+	tables := newBalanceTables(t, 6, 5, 3)
+
+	moves := PlanBalance(tables, allMovable)
+	assert.Len(t, moves, 3)
+	assert.Equal(t, 0, tables[2].PlayerCount())
+	assert.Equal(t, 7, tables[0].PlayerCount())
+	assert.Equal(t, 7, tables[1].PlayerCount())
+}
+
+func TestPlanBalanceOnlyMovesIdleTables(t *testing.T) {
+	tables := newBalanceTables(t, 9, 5)
+
+	moves := PlanBalance(tables, func(table *Table) bool { return table.ID == "1-2" })
+	assert.Empty(t, moves)
+}
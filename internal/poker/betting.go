@@ -0,0 +1,232 @@
+package poker
+
+import "fmt"
+
+// PlayerAction is a decision sent by the player whose turn it is.
+type PlayerAction struct {
+	PlayerID string
+	Action   string // "check", "call", "raise", "allin", "fold"
+	Amount   int    // chips put in by a raise; calls always put in the call amount
+}
+
+// StartBettingRound prepares the betting round of the current stage and gives
+// the turn to the first player to act. It returns false when nobody is left
+// to act, for example when every player is all in.
+func (table *Table) StartBettingRound() (bool, error) {
+	table.LastToRaiserIndex = -1
+	table.MinRaise = table.BBValue
+	bbIndex := table.SeatIndexOf(table.CurrentBB)
+	if bbIndex == -1 {
+		return false, fmt.Errorf("No se encontró el jugador con Big Blind en la mesa")
+	}
+
+	startingPlayerIndex := -1
+	seatCount := len(table.Seats)
+	if table.CurrentStage == "preFlop" {
+		table.SetSMBB()
+		startingPlayerIndex = (bbIndex + 1) % seatCount
+	} else {
+		for i := 1; i < seatCount; i++ {
+			currentIndex := (bbIndex + i) % seatCount
+			if table.canAct(currentIndex) {
+				startingPlayerIndex = currentIndex
+				break
+			}
+		}
+	}
+
+	if table.AllPlayersAllInExceptOneAndFolded() || table.AllPlayersAllInExceptFolded() {
+		table.finishBettingRound()
+		return false, nil
+	}
+	if startingPlayerIndex == -1 {
+		return false, fmt.Errorf("No se encontró un jugador válido para iniciar la ronda")
+	}
+
+	table.RoundStartIndex = startingPlayerIndex
+	table.TurnIndex = startingPlayerIndex
+	return table.nextTurn(), nil
+}
+
+// ApplyAction plays the action of the player whose turn it is and moves the
+// turn on. It returns false once the betting round is over.
+func (table *Table) ApplyAction(action PlayerAction) (bool, error) {
+	player := table.turnPlayer()
+	if player == nil || player.ID != action.PlayerID {
+		return true, fmt.Errorf("it is not the turn of player %s", action.PlayerID)
+	}
+	if !containsString(player.AvailableActions, action.Action) {
+		return true, fmt.Errorf("action %q is not available to player %s", action.Action, action.PlayerID)
+	}
+	if action.Action == "raise" {
+		if err := table.checkRaise(player, action.Amount); err != nil {
+			return true, err
+		}
+	}
+
+	player.IsTurn = false
+	player.LastAction = action.Action
+	raiseOccurred := false
+	committed := player.TotalBet
+
+	switch action.Action {
+	case "raise":
+		raiseOccurred = true
+		table.MinRaise = action.Amount - player.CallAmount
+		table.raised(player)
+		player.TotalBet += action.Amount
+		player.Chips -= action.Amount
+		table.BiggestBet = player.TotalBet
+		player.CallAmount -= action.Amount
+		table.TotalBet += action.Amount
+		table.SetTablePlayersCallAmount()
+	case "fold":
+		table.PlayerActedInRound++
+		player.HasFold = true
+	case "call":
+		amount := min(player.CallAmount, player.Chips)
+		player.TotalBet += amount
+		player.Chips -= amount
+		player.CallAmount -= amount
+		table.TotalBet += amount
+		player.HasFold = false
+		if player.Chips == 0 {
+			player.HasAllIn = true
+		}
+		table.PlayerActedInRound++
+	case "allin":
+		player.HasAllIn = true
+		player.TotalBet += player.Chips
+		table.TotalBet += player.Chips
+		table.PlayerActedInRound++
+		if player.Chips > player.CallAmount {
+			table.MinRaise = max(table.MinRaise, player.Chips-player.CallAmount)
+			table.BiggestBet = player.TotalBet
+			raiseOccurred = true
+			table.raised(player)
+			table.SetTablePlayersCallAmount()
+		}
+		player.CallAmount -= player.Chips
+		player.Chips = 0
+	case "check":
+		table.PlayerActedInRound++
+	}
+	table.recordAction(player, player.TotalBet-committed, false)
+
+	return table.advanceTurn(raiseOccurred), nil
+}
+
+// checkRaise rejects a raise of amount chips that does not raise the bet by
+// at least MinRaise, or that takes every chip or more, which is an all in.
+func (table *Table) checkRaise(player *Player, amount int) error {
+	if amount <= 0 {
+		return fmt.Errorf("raise amount must be positive, got %d", amount)
+	}
+	if amount >= player.Chips {
+		return fmt.Errorf("raise of %d must be below the %d chips of player %s, go all in instead", amount, player.Chips, player.ID)
+	}
+	if amount-player.CallAmount < table.MinRaise {
+		return fmt.Errorf("raise of %d is below the minimum of %d", amount, player.CallAmount+table.MinRaise)
+	}
+	return nil
+}
+
+// TimeoutTurn checks for the player whose turn timer ran out, or folds them
+// when they are facing a bet.
+func (table *Table) TimeoutTurn() bool {
+	player := table.turnPlayer()
+	if player == nil {
+		return false
+	}
+
+	player.IsTurn = false
+	player.LastAction = "fold"
+	player.HasFold = true
+	if player.CallAmount <= 0 {
+		player.LastAction = "check"
+		player.HasFold = false
+	}
+	table.PlayerActedInRound++
+	table.recordAction(player, 0, true)
+
+	return table.advanceTurn(false)
+}
+
+// raised reopens the betting after player raised.
+func (table *Table) raised(player *Player) {
+	table.LastToRaiserIndex = table.TurnIndex
+	table.RoundStartIndex = table.TurnIndex
+	table.PlayerActedInRound = 1
+	for _, other := range table.SeatedPlayers() {
+		if other.ID != player.ID && !other.HasFold && !other.HasAllIn {
+			other.LastAction = ""
+		}
+	}
+}
+
+func (table *Table) advanceTurn(raiseOccurred bool) bool {
+	table.AllPlayersExceptOneFold()
+	table.TurnIndex = (table.TurnIndex + 1) % len(table.Seats)
+
+	if table.AllPlayersAllInExceptFolded() || table.AllFoldExceptOne {
+		table.finishBettingRound()
+		return false
+	}
+	if !raiseOccurred && (table.TurnIndex == table.LastToRaiserIndex || table.TurnIndex == table.RoundStartIndex) {
+		table.finishBettingRound()
+		return false
+	}
+	if raiseOccurred {
+		table.PlayerActedInRound = 1
+	}
+	if table.PlayerActedInRound == table.PlayerCount() {
+		table.finishBettingRound()
+		return false
+	}
+
+	return table.nextTurn()
+}
+
+// nextTurn gives the turn to the first player from TurnIndex on who can still
+// act.
+func (table *Table) nextTurn() bool {
+	for range table.Seats {
+		if table.canAct(table.TurnIndex) {
+			player := table.Seats[table.TurnIndex].Player
+			player.IsTurn = true
+			table.CurrentTurn = player.ID
+			table.SetTablePlayerActions(table.TurnIndex)
+			return true
+		}
+		table.TurnIndex = (table.TurnIndex + 1) % len(table.Seats)
+		if table.TurnIndex == table.RoundStartIndex && table.PlayerActedInRound >= table.CountActivePlayers() {
+			break
+		}
+	}
+
+	table.finishBettingRound()
+	return false
+}
+
+func (table *Table) finishBettingRound() {
+	for _, player := range table.SeatedPlayers() {
+		player.IsTurn = false
+	}
+	table.PlayerActedInRound = 0
+}
+
+func (table *Table) turnPlayer() *Player {
+	if table.TurnIndex < 0 || table.TurnIndex >= len(table.Seats) {
+		return nil
+	}
+	player := table.Seats[table.TurnIndex].Player
+	if player == nil || player.ID != table.CurrentTurn || !player.IsTurn {
+		return nil
+	}
+	return player
+}
+
+func (table *Table) canAct(seatIndex int) bool {
+	player := table.Seats[seatIndex].Player
+	return player != nil && !player.SittingOut && !player.HasFold && !player.HasAllIn && !player.IsEliminated
+}
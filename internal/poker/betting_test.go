@@ -0,0 +1,116 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFlopTable(t *testing.T) *Table {
+	table := newTestTable(t,
+		Player{ID: "player1", Chips: 1000}, Player{ID: "player2", Chips: 1000}, Player{ID: "player3", Chips: 1000},
+	)
+	table.CurrentBB = "player2"
+	table.BBValue = 20
+	table.CurrentStage = "flop"
+	return table
+}
+
+func TestBettingRoundChecksAround(t *testing.T) {
+	table := newFlopTable(t)
+
+	more, err := table.StartBettingRound()
+	assert.NoError(t, err)
+	assert.True(t, more)
+
+	for _, id := range []string{"player3", "player1", "player2"} {
+		assert.Equal(t, id, table.CurrentTurn, "Turn should follow the big blind")
+		more, err = table.ApplyAction(PlayerAction{PlayerID: id, Action: "check"})
+		assert.NoError(t, err)
+	}
+	assert.False(t, more, "Round should end once everyone checked")
+	assert.Equal(t, 0, table.PlayerActedInRound)
+}
+
+func TestBettingRoundRaiseReopensAction(t *testing.T) {
+	table := newFlopTable(t)
+	_, err := table.StartBettingRound()
+	assert.NoError(t, err)
+
+	more, err := table.ApplyAction(PlayerAction{PlayerID: "player3", Action: "raise", Amount: 100})
+	assert.NoError(t, err)
+	assert.True(t, more)
+	assert.Equal(t, 100, table.BiggestBet)
+
+	more, err = table.ApplyAction(PlayerAction{PlayerID: "player1", Action: "call", Amount: 100})
+	assert.NoError(t, err)
+	assert.True(t, more)
+
+	more, err = table.ApplyAction(PlayerAction{PlayerID: "player2", Action: "fold"})
+	assert.NoError(t, err)
+	assert.False(t, more, "Round should end when the action is back on the raiser")
+	assert.Equal(t, 900, table.FindPlayer("player1").Chips)
+	assert.True(t, table.FindPlayer("player2").HasFold)
+}
+
+func TestBettingRoundRejectsOutOfTurnAction(t *testing.T) {
+	table := newFlopTable(t)
+	_, err := table.StartBettingRound()
+	assert.NoError(t, err)
+
+	_, err = table.ApplyAction(PlayerAction{PlayerID: "player1", Action: "check"})
+	assert.Error(t, err)
+	_, err = table.ApplyAction(PlayerAction{PlayerID: "player3", Action: "call", Amount: 20})
+	assert.Error(t, err, "Call is not available when nobody bet")
+	assert.Equal(t, "player3", table.CurrentTurn, "Turn should not move on a rejected action")
+}
+
+func TestBettingRoundTimeout(t *testing.T) {
+	table := newFlopTable(t)
+	_, err := table.StartBettingRound()
+	assert.NoError(t, err)
+	_, err = table.ApplyAction(PlayerAction{PlayerID: "player3", Action: "raise", Amount: 100})
+	assert.NoError(t, err)
+
+	more := table.TimeoutTurn()
+	assert.True(t, more)
+	assert.True(t, table.FindPlayer("player1").HasFold, "Player facing a bet should fold on timeout")
+
+	table.TimeoutTurn()
+	assert.True(t, table.AllFoldExceptOne)
+}
+
+func TestBettingRoundCallsTheCallAmount(t *testing.T) {
+	table := newFlopTable(t)
+	_, err := table.StartBettingRound()
+	assert.NoError(t, err)
+	_, err = table.ApplyAction(PlayerAction{PlayerID: "player3", Action: "raise", Amount: 100})
+	assert.NoError(t, err)
+
+	_, err = table.ApplyAction(PlayerAction{PlayerID: "player1", Action: "call", Amount: 5000})
+	assert.NoError(t, err)
+	assert.Equal(t, 900, table.FindPlayer("player1").Chips, "An over-stack call should only put in the call amount")
+
+	_, err = table.ApplyAction(PlayerAction{PlayerID: "player2", Action: "call", Amount: -50})
+	assert.NoError(t, err)
+	assert.Equal(t, 900, table.FindPlayer("player2").Chips, "A negative call should still put in the call amount")
+	assert.Equal(t, 300, table.TotalBet)
+}
+
+func TestBettingRoundRejectsInvalidRaises(t *testing.T) {
+	table := newFlopTable(t)
+	_, err := table.StartBettingRound()
+	assert.NoError(t, err)
+
+	for _, amount := range []int{-100, 0, 10, 1000, 5000} {
+		_, err = table.ApplyAction(PlayerAction{PlayerID: "player3", Action: "raise", Amount: amount})
+		assert.Error(t, err, "Raise of %d should be rejected", amount)
+	}
+	assert.Equal(t, 1000, table.FindPlayer("player3").Chips)
+	assert.Equal(t, "player3", table.CurrentTurn, "A rejected raise should keep the turn")
+
+	_, err = table.ApplyAction(PlayerAction{PlayerID: "player3", Action: "raise", Amount: 100})
+	assert.NoError(t, err)
+	_, err = table.ApplyAction(PlayerAction{PlayerID: "player1", Action: "raise", Amount: 150})
+	assert.ErrorContains(t, err, "below the minimum of 200", "A re-raise should be at least the size of the last raise")
+}
@@ -0,0 +1,119 @@
+package poker
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BlindLevel is one step of a tournament blind structure. A break level has
+// no blinds and no hands are dealt while it runs.
+type BlindLevel struct {
+	SmallBlind int
+	BigBlind   int
+	Ante       int
+	Minutes    int
+	Break      bool
+}
+
+func (level BlindLevel) Duration() time.Duration {
+	return time.Duration(level.Minutes) * time.Minute
+}
+
+type BlindStructure struct {
+	Levels []BlindLevel
+}
+
+func (structure BlindStructure) Validate() error {
+	if len(structure.Levels) == 0 {
+		return fmt.Errorf("blind structure has no levels")
+	}
+	for i, level := range structure.Levels {
+		if level.Minutes <= 0 && i != len(structure.Levels)-1 {
+			return fmt.Errorf("level %d has no duration", i+1)
+		}
+		if level.Break {
+			if level.Minutes <= 0 {
+				return fmt.Errorf("break at level %d has no duration", i+1)
+			}
+			continue
+		}
+		if level.SmallBlind <= 0 || level.BigBlind < level.SmallBlind || level.Ante < 0 {
+			return fmt.Errorf("level %d has invalid blinds %d/%d ante %d", i+1, level.SmallBlind, level.BigBlind, level.Ante)
+		}
+	}
+	if structure.Levels[0].Break {
+		return fmt.Errorf("blind structure cannot start with a break")
+	}
+	if structure.Levels[len(structure.Levels)-1].Break {
+		return fmt.Errorf("blind structure cannot end with a break")
+	}
+	return nil
+}
+
+// Next returns the level that follows index, if any.
+func (structure BlindStructure) Next(index int) (BlindLevel, bool) {
+	if index+1 >= len(structure.Levels) {
+		return BlindLevel{}, false
+	}
+	return structure.Levels[index+1], true
+}
+
+// TournamentConfig is the typed content of a tournament's Configuration.
+type TournamentConfig struct {
+	SitAndGo          bool
+	StartingChips     int
+	Blinds            BlindStructure
+	BuyIn             int
+	Bounty            int // part of BuyIn put on the player's head
+	ProgressiveBounty bool
+	Guarantee         int
+	Payouts           PayoutStructure
+
+	LateRegistrationLevel int // last level open to registration and re-entry
+	MaxReEntries          int
+	RebuyLevels           int // rebuys are open through this level
+	RebuyChips            int
+	RebuyCost             int
+	MaxRebuys             int // 0 means unlimited
+	AddOnChips            int
+	AddOnCost             int
+}
+
+func ParseTournamentConfig(data string) (TournamentConfig, error) {
+	var cfg TournamentConfig
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid tournament configuration: %w", err)
+	}
+	if err := cfg.Blinds.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Payouts.Validate()
+}
+
+// ClockState is what the tournament clock broadcasts: the level in play, the
+// one after it and how long is left.
+type ClockState struct {
+	TournamentID     string
+	LevelIndex       int
+	Level            BlindLevel
+	Next             *BlindLevel `json:",omitempty"`
+	RemainingSeconds int
+	Paused           bool
+	LevelEndsAt      time.Time
+}
+
+func ClockSubject(tournamentID string) string {
+	return fmt.Sprintf("pokerServer.clock.%s", tournamentID)
+}
+
+// ApplyBlindLevel sets the blinds and ante used from the next hand on.
+func (table *Table) ApplyBlindLevel(levelIndex int, level BlindLevel) {
+	if level.Break {
+		return
+	}
+	table.BlindLevel = levelIndex + 1
+	table.SBValue = level.SmallBlind
+	table.BBValue = level.BigBlind
+	table.AnteValue = level.Ante
+}
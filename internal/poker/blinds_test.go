@@ -0,0 +1,68 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlindStructureValidate(t *testing.T) {
+	valid := BlindStructure{Levels: []BlindLevel{
+		{SmallBlind: 10, BigBlind: 20, Minutes: 15},
+		{Minutes: 5, Break: true},
+		{SmallBlind: 20, BigBlind: 40, Ante: 5, Minutes: 15},
+	}}
+	assert.NoError(t, valid.Validate())
+
+	next, ok := valid.Next(0)
+	assert.True(t, ok)
+	assert.True(t, next.Break)
+	_, ok = valid.Next(2)
+	assert.False(t, ok)
+
+	assert.Error(t, BlindStructure{}.Validate())
+	assert.Error(t, BlindStructure{Levels: []BlindLevel{{Minutes: 5, Break: true}}}.Validate())
+	assert.Error(t, BlindStructure{Levels: []BlindLevel{{SmallBlind: 20, BigBlind: 10, Minutes: 5}}}.Validate())
+	assert.Error(t, BlindStructure{Levels: []BlindLevel{
+		{SmallBlind: 10, BigBlind: 20, Minutes: 15},
+		{Minutes: 5, Break: true},
+	}}.Validate())
+	assert.Error(t, BlindStructure{Levels: []BlindLevel{
+		{SmallBlind: 10, BigBlind: 20, Minutes: 15},
+		{Break: true},
+		{SmallBlind: 20, BigBlind: 40},
+	}}.Validate())
+}
+
+func TestParseTournamentConfig(t *testing.T) {
+	cfg, err := ParseTournamentConfig(`{"StartingChips":1500,"Blinds":{"Levels":[{"SmallBlind":10,"BigBlind":20,"Minutes":10}]}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 1500, cfg.StartingChips)
+	assert.Equal(t, 20, cfg.Blinds.Levels[0].BigBlind)
+
+	_, err = ParseTournamentConfig(`not json`)
+	assert.Error(t, err)
+}
+
+func TestAntesGoToThePot(t *testing.T) {
+	table := newTestTable(t,
+		Player{ID: "a", Chips: 1000},
+		Player{ID: "b", Chips: 1000},
+		Player{ID: "c", Chips: 3},
+	)
+	table.ApplyBlindLevel(2, BlindLevel{SmallBlind: 50, BigBlind: 100, Ante: 10, Minutes: 10})
+	assert.Equal(t, 3, table.BlindLevel)
+	assert.Equal(t, 10, table.AnteValue)
+
+	table.PostAntes()
+	assert.Equal(t, 23, table.TotalBet)
+	assert.True(t, table.FindPlayer("c").HasAllIn)
+
+	pots := table.BuildPots()
+	assert.Equal(t, 9, pots[0].Amount)
+	assert.Equal(t, 14, pots[1].Amount)
+
+	table.VoidHand("test")
+	assert.Equal(t, 1000, table.FindPlayer("a").Chips)
+	assert.Equal(t, 3, table.FindPlayer("c").Chips)
+}
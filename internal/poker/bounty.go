@@ -0,0 +1,51 @@
+package poker
+
+// AwardBounties pays the bounty of every player who busted in the hand to
+// the winners of the last pot that player was eligible for, the pot that
+// actually knocked them out. Winners of a split pot share the bounty, odd
+// chips first. On progressive tables half of each share is added to the
+// winner's own bounty. Must run after the pots are settled.
+func (table *Table) AwardBounties() {
+	for _, busted := range table.DealtPlayers() {
+		if busted.Chips > 0 || busted.Bounty == 0 {
+			continue
+		}
+
+		var knockout *Pot
+		for i := range table.Pots {
+			if containsString(table.Pots[i].Eligible, busted.ID) {
+				knockout = &table.Pots[i]
+			}
+		}
+		if knockout == nil || len(knockout.Winners) == 0 || containsString(knockout.Winners, busted.ID) {
+			continue
+		}
+
+		bounty := busted.Bounty
+		busted.Bounty = 0
+		share := bounty / len(knockout.Winners)
+		oddChips := bounty % len(knockout.Winners)
+		for i, id := range knockout.Winners {
+			amount := share
+			if i < oddChips {
+				amount++
+			}
+			winner := table.FindPlayer(id)
+			if table.ProgressiveBounty {
+				head := amount / 2
+				winner.Bounty += head
+				amount -= head
+			}
+			winner.BountiesWon += amount
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBountyGoesToWinnerOfKnockoutPot(t *testing.T) {
+	table := newTestTable(t,
+		Player{ID: "short", TotalBet: 100, HasAllIn: true, HandScore: 30, Bounty: 50},
+		Player{ID: "medium", TotalBet: 300, HasAllIn: true, HandScore: 20, Bounty: 50},
+		Player{ID: "big", Chips: 100, TotalBet: 300, HandScore: 10, Bounty: 50},
+	)
+	table.GameType = GameTournament
+	table.SBSeat = 1
+
+	table.SettlePots()
+
+	// big wins both pots, so it knocks out short and medium.
+	assert.Equal(t, 100, table.FindPlayer("big").BountiesWon)
+	assert.Equal(t, 0, table.FindPlayer("short").Bounty)
+	assert.Equal(t, 0, table.FindPlayer("medium").Bounty)
+}
+
+func TestBountyFollowsSidePot(t *testing.T) {
+	table := newTestTable(t,
+		Player{ID: "short", TotalBet: 100, HasAllIn: true, HandScore: 10, Bounty: 50},
+		Player{ID: "medium", TotalBet: 300, HasAllIn: true, HandScore: 30, Bounty: 50},
+		Player{ID: "big", Chips: 100, TotalBet: 300, HandScore: 20, Bounty: 50},
+	)
+	table.GameType = GameTournament
+	table.SBSeat = 1
+
+	table.SettlePots()
+
+	// short wins the main pot and survives; big takes the side pot that
+	// busted medium.
+	assert.Equal(t, 50, table.FindPlayer("big").BountiesWon)
+	assert.Equal(t, 0, table.FindPlayer("short").BountiesWon)
+}
+
+func TestProgressiveBountySplit(t *testing.T) {
+	table := newTestTable(t,
+		Player{ID: "busted", TotalBet: 100, HasAllIn: true, HandScore: 30, Bounty: 101},
+		Player{ID: "a", Chips: 100, TotalBet: 100, HandScore: 10, Bounty: 50},
+		Player{ID: "b", Chips: 100, TotalBet: 100, HandScore: 10, Bounty: 50},
+	)
+	table.GameType = GameTournament
+	table.ProgressiveBounty = true
+	table.SBSeat = 1
+
+	table.SettlePots()
+
+	a, b := table.FindPlayer("a"), table.FindPlayer("b")
+	assert.Equal(t, 101, a.BountiesWon+a.Bounty-50+b.BountiesWon+b.Bounty-50)
+	assert.Equal(t, 25, b.BountiesWon)
+	assert.Equal(t, 75, b.Bounty)
+	assert.Equal(t, 26, a.BountiesWon)
+	assert.Equal(t, 75, a.Bounty)
+}
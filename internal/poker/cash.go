@@ -0,0 +1,166 @@
+package poker
+
+import "fmt"
+
+const (
+	GameTournament = "tournament"
+	GameCash       = "cash"
+)
+
+type CashConfig struct {
+	SmallBlind int
+	BigBlind   int
+	MinBuyInBB int
+	MaxBuyInBB int
+	Rake       RakeConfig
+}
+
+func (cfg CashConfig) MinBuyIn() int {
+	return cfg.MinBuyInBB * cfg.BigBlind
+}
+
+func (cfg CashConfig) MaxBuyIn() int {
+	return cfg.MaxBuyInBB * cfg.BigBlind
+}
+
+func (cfg CashConfig) Validate() error {
+	if cfg.SmallBlind <= 0 || cfg.BigBlind < cfg.SmallBlind {
+		return fmt.Errorf("invalid blinds %d/%d", cfg.SmallBlind, cfg.BigBlind)
+	}
+	if cfg.MinBuyInBB <= 0 || cfg.MaxBuyInBB < cfg.MinBuyInBB {
+		return fmt.Errorf("invalid buy-in range %d-%d BB", cfg.MinBuyInBB, cfg.MaxBuyInBB)
+	}
+	return cfg.Rake.Validate()
+}
+
+// NewCashTable creates an empty cash game table with the blinds taken from cfg.
+func NewCashTable(id string, maxSeats int, cfg CashConfig) (Table, error) {
+	if err := cfg.Validate(); err != nil {
+		return Table{}, err
+	}
+
+	table, err := NewTable(id, maxSeats)
+	if err != nil {
+		return Table{}, err
+	}
+	table.GameType = GameCash
+	table.Cash = cfg
+	table.SBValue = cfg.SmallBlind
+	table.BBValue = cfg.BigBlind
+
+	return table, nil
+}
+
+// CheckJoin validates a cash game seat request without changing the table,
+// so the buy-in can be collected before the player sits down.
+func (table *Table) CheckJoin(seatNumber int, playerID string, buyIn int) error {
+	if table.GameType != GameCash {
+		return fmt.Errorf("table %s is not a cash game table", table.ID)
+	}
+	if table.FindPlayer(playerID) != nil {
+		return fmt.Errorf("player %s is already seated on table %s", playerID, table.ID)
+	}
+	if seatNumber == 0 {
+		if len(table.OpenSeats()) == 0 {
+			return fmt.Errorf("table %s is full", table.ID)
+		}
+	} else if seatNumber < 0 || seatNumber > len(table.Seats) || !table.Seats[seatNumber-1].IsEmpty() {
+		return fmt.Errorf("seat %d is not available on table %s", seatNumber, table.ID)
+	} else if reserved := table.Seats[seatNumber-1].ReservedFor; reserved != "" && reserved != playerID {
+		return fmt.Errorf("seat %d on table %s is reserved", seatNumber, table.ID)
+	}
+	if buyIn < table.Cash.MinBuyIn() || buyIn > table.Cash.MaxBuyIn() {
+		return fmt.Errorf("buy-in %d outside of %d-%d", buyIn, table.Cash.MinBuyIn(), table.Cash.MaxBuyIn())
+	}
+	return nil
+}
+
+func (table *Table) StakeKey() string {
+	return StakeWaitingListKey(table.Cash.SmallBlind, table.Cash.BigBlind)
+}
+
+// JoinCash seats a player mid-session with buyIn chips. A seatNumber of 0
+// takes the first empty seat. The player sits out until the big blind reaches
+// them, or posts a big blind on the next hand when waitForBB is false.
+func (table *Table) JoinCash(seatNumber int, player Player, buyIn int, waitForBB bool) (int, error) {
+	if err := table.CheckJoin(seatNumber, player.ID, buyIn); err != nil {
+		return 0, err
+	}
+
+	player.Chips = buyIn
+	player.SittingOut = true
+	player.WaitForBB = waitForBB
+	player.PostBlind = !waitForBB
+
+	if seatNumber == 0 {
+		return table.SitPlayerAnywhere(player)
+	}
+	return seatNumber, table.SitPlayer(seatNumber, player)
+}
+
+// CheckTopUp validates adding amount chips to a player's stack between hands.
+// A player who busted rebuys with the same call and must bring at least the
+// minimum buy-in.
+func (table *Table) CheckTopUp(playerID string, amount int) error {
+	player := table.FindPlayer(playerID)
+	if player == nil {
+		return fmt.Errorf("player %s is not seated on table %s", playerID, table.ID)
+	}
+	if amount <= 0 {
+		return fmt.Errorf("invalid top-up amount %d", amount)
+	}
+	if player.Chips+amount > table.Cash.MaxBuyIn() {
+		return fmt.Errorf("top-up of %d would exceed the maximum buy-in of %d", amount, table.Cash.MaxBuyIn())
+	}
+	if player.Chips == 0 && amount < table.Cash.MinBuyIn() {
+		return fmt.Errorf("rebuy of %d is below the minimum buy-in of %d", amount, table.Cash.MinBuyIn())
+	}
+	return nil
+}
+
+func (table *Table) TopUp(playerID string, amount int) error {
+	if err := table.CheckTopUp(playerID, amount); err != nil {
+		return err
+	}
+	player := table.FindPlayer(playerID)
+	if player.Chips == 0 {
+		player.WaitForBB = true
+	}
+	player.Chips += amount
+	return nil
+}
+
+// LeaveCash stands the player up and returns the chips to credit back.
+func (table *Table) LeaveCash(playerID string) (Player, error) {
+	player, ok := table.StandUp(playerID)
+	if !ok {
+		return Player{}, fmt.Errorf("player %s is not seated on table %s", playerID, table.ID)
+	}
+	return player, nil
+}
+
+// ReadyPlayerCount returns how many seated players could be dealt into the
+// next hand.
+func (table *Table) ReadyPlayerCount() int {
+	count := 0
+	for _, player := range table.SeatedPlayers() {
+		if player.Chips > 0 && !player.IsEliminated {
+			count++
+		}
+	}
+	return count
+}
+
+// PrepareCashHand deals back in the players that were waiting for the big
+// blind once it reaches them, and those who chose to post.
+func (table *Table) PrepareCashHand() {
+	for _, player := range table.SeatedPlayers() {
+		if !player.SittingOut || player.Chips <= 0 {
+			continue
+		}
+		if player.PostBlind || (player.WaitForBB && player.ID == table.CurrentBB) {
+			player.SittingOut = false
+			player.WaitForBB = false
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCashTable(t *testing.T) *Table {
+	table, err := NewCashTable("cash", 6, CashConfig{
+		SmallBlind: 1,
+		BigBlind:   2,
+		MinBuyInBB: 40,
+		MaxBuyInBB: 100,
+		Rake:       RakeConfig{Rate: 500, Cap: 6},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create cash table: %v", err)
+	}
+	return &table
+}
+
+func TestJoinCashBuyInLimits(t *testing.T) {
+	table := newTestCashTable(t)
+
+	_, err := table.JoinCash(1, Player{ID: "player1"}, 50, true)
+	assert.Error(t, err, "Buy-in below the minimum should be rejected")
+	_, err = table.JoinCash(1, Player{ID: "player1"}, 250, true)
+	assert.Error(t, err, "Buy-in above the maximum should be rejected")
+
+	seat, err := table.JoinCash(3, Player{ID: "player1"}, 200, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, seat)
+	assert.True(t, table.FindPlayer("player1").SittingOut, "New players sit out until dealt in")
+
+	assert.Error(t, table.TopUp("player1", 1), "Top-up above the maximum should be rejected")
+	table.FindPlayer("player1").Chips = 150
+	assert.NoError(t, table.TopUp("player1", 50))
+	assert.Equal(t, 200, table.FindPlayer("player1").Chips)
+}
+
+func TestWaitForBigBlind(t *testing.T) {
+	table := newTestCashTable(t)
+	for i, id := range []string{"player1", "player2", "player3"} {
+		_, err := table.JoinCash(i+1, Player{ID: id}, 100, false)
+		assert.NoError(t, err)
+		table.FindPlayer(id).SittingOut = false
+		table.FindPlayer(id).PostBlind = false
+	}
+	table.CurrentSB = "player1"
+	table.CurrentBB = "player2"
+	table.SBSeat = 1
+
+	_, err := table.JoinCash(4, Player{ID: "waiting"}, 100, true)
+	assert.NoError(t, err)
+
+	table.SMBBTurn()
+	table.PrepareCashHand()
+	assert.Equal(t, "player3", table.CurrentBB)
+	assert.True(t, table.FindPlayer("waiting").SittingOut, "Player should wait for the big blind")
+
+	table.SMBBTurn()
+	table.PrepareCashHand()
+	assert.Equal(t, "waiting", table.CurrentBB)
+	assert.False(t, table.FindPlayer("waiting").SittingOut, "Player should be dealt in on the big blind")
+
+	left, err := table.LeaveCash("player2")
+	assert.NoError(t, err)
+	assert.Equal(t, 100, left.Chips)
+	assert.True(t, table.Seats[1].IsEmpty())
+}
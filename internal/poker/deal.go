@@ -0,0 +1,189 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	DealICM      = "icm"
+	DealChipChop = "chipChop"
+
+	// maxDealPlayers bounds the ICM calculation, which grows with 2^players.
+	maxDealPlayers = 12
+)
+
+type DealShare struct {
+	PlayerID string
+	WalletID uint
+	Entry    int
+	Chips    int
+	Amount   int
+}
+
+// Deal splits the remaining prizes between the players left. Remainder is
+// kept out of the split and goes to whoever wins the rest of the tournament.
+type Deal struct {
+	Method    string
+	Shares    []DealShare
+	Remainder int
+}
+
+func (deal Deal) AmountFor(playerID string) (int, bool) {
+	for _, share := range deal.Shares {
+		if share.PlayerID == playerID {
+			return share.Amount, true
+		}
+	}
+	return 0, false
+}
+
+// ProposeDeal works out a split of prizes, the payouts still to be won from
+// first place down, between players using ICM or a chip chop.
+func ProposeDeal(method string, players []Player, prizes []int, remainder int) (Deal, error) {
+	if len(players) < 2 || len(players) > maxDealPlayers {
+		return Deal{}, fmt.Errorf("deals need 2 to %d players, got %d", maxDealPlayers, len(players))
+	}
+	prizes = append([]int(nil), prizes...)
+	for len(prizes) < len(players) {
+		prizes = append(prizes, 0)
+	}
+	prizes = prizes[:len(players)]
+	if remainder < 0 || remainder > prizes[0]-prizes[1] {
+		return Deal{}, fmt.Errorf("remainder %d must be between 0 and %d", remainder, prizes[0]-prizes[1])
+	}
+	prizes[0] -= remainder
+
+	stacks := make([]int, len(players))
+	for i, player := range players {
+		stacks[i] = player.Chips
+	}
+
+	var amounts []int
+	switch method {
+	case DealICM:
+		amounts = roundShares(ICM(stacks, prizes), sum(prizes))
+	case DealChipChop:
+		var err error
+		if amounts, err = ChipChop(stacks, prizes); err != nil {
+			return Deal{}, err
+		}
+	default:
+		return Deal{}, fmt.Errorf("unknown deal method %q", method)
+	}
+
+	deal := Deal{Method: method, Remainder: remainder}
+	for i, player := range players {
+		deal.Shares = append(deal.Shares, DealShare{
+			PlayerID: player.ID,
+			WalletID: player.WalletID,
+			Entry:    player.Entry,
+			Chips:    player.Chips,
+			Amount:   amounts[i],
+		})
+	}
+	return deal, nil
+}
+
+// ICM returns the expected prize of every stack under the Independent Chip
+// Model: the chance of finishing first is the share of chips in play, and the
+// same holds for every lower place among the stacks left.
+func ICM(stacks []int, prizes []int) []float64 {
+	n := len(stacks)
+	memo := make(map[int][]float64)
+
+	var equity func(mask int) []float64
+	equity = func(mask int) []float64 {
+		if result, ok := memo[mask]; ok {
+			return result
+		}
+		result := make([]float64, n)
+		place := n - popCount(mask)
+		if place >= len(prizes) || mask == 0 {
+			memo[mask] = result
+			return result
+		}
+
+		total := 0
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 {
+				total += stacks[i]
+			}
+		}
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) == 0 {
+				continue
+			}
+			chance := 1 / float64(popCount(mask))
+			if total > 0 {
+				chance = float64(stacks[i]) / float64(total)
+			}
+			result[i] += chance * float64(prizes[place])
+			for j, value := range equity(mask &^ (1 << i)) {
+				result[j] += chance * value
+			}
+		}
+		memo[mask] = result
+		return result
+	}
+
+	return equity(1<<n - 1)
+}
+
+// ChipChop guarantees every player the lowest prize left and splits the rest
+// in proportion to chips.
+func ChipChop(stacks []int, prizes []int) ([]int, error) {
+	if len(stacks) == 0 || len(prizes) < len(stacks) {
+		return nil, fmt.Errorf("chip chop needs a prize for each of %d players, got %d", len(stacks), len(prizes))
+	}
+	chips := sum(stacks)
+	if chips <= 0 {
+		return nil, fmt.Errorf("chip chop needs chips in play, got %d", chips)
+	}
+	floor := prizes[len(stacks)-1]
+	rest := sum(prizes) - floor*len(stacks)
+
+	shares := make([]float64, len(stacks))
+	for i, stack := range stacks {
+		shares[i] = float64(floor) + float64(rest)*float64(stack)/float64(chips)
+	}
+	return roundShares(shares, sum(prizes)), nil
+}
+
+// roundShares rounds shares down and hands the chips left over to the
+// largest fractions, so the amounts add up to total.
+func roundShares(shares []float64, total int) []int {
+	amounts := make([]int, len(shares))
+	order := make([]int, len(shares))
+	left := total
+	for i, share := range shares {
+		amounts[i] = int(math.Floor(share + 1e-9))
+		left -= amounts[i]
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return shares[order[a]]-math.Floor(shares[order[a]]) > shares[order[b]]-math.Floor(shares[order[b]])
+	})
+	for i := 0; left > 0 && len(order) > 0; i = (i + 1) % len(order) {
+		amounts[order[i]]++
+		left--
+	}
+	return amounts
+}
+
+func popCount(mask int) int {
+	count := 0
+	for ; mask != 0; mask &= mask - 1 {
+		count++
+	}
+	return count
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
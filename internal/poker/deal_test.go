@@ -0,0 +1,58 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestICM(t *testing.T) {
+	equity := ICM([]int{5000, 3000, 2000}, []int{50, 30, 20})
+
+	assert.InDelta(t, 38.39, equity[0], 0.01)
+	assert.InDelta(t, 32.76, equity[1], 0.01)
+	assert.InDelta(t, 28.85, equity[2], 0.01)
+	assert.InDelta(t, 100, equity[0]+equity[1]+equity[2], 0.0001)
+
+	even := ICM([]int{1000, 1000}, []int{70, 30})
+	assert.InDelta(t, 50, even[0], 0.0001)
+}
+
+func TestProposeDeal(t *testing.T) {
+	players := []Player{
+		{ID: "a", Chips: 5000},
+		{ID: "b", Chips: 3000},
+		{ID: "c", Chips: 2000},
+	}
+
+	deal, err := ProposeDeal(DealChipChop, players, []int{500, 300, 200}, 0)
+	assert.NoError(t, err)
+	amounts := []int{deal.Shares[0].Amount, deal.Shares[1].Amount, deal.Shares[2].Amount}
+	assert.Equal(t, []int{400, 320, 280}, amounts)
+
+	deal, err = ProposeDeal(DealICM, players, []int{500, 300, 200}, 100)
+	assert.NoError(t, err)
+	total := 0
+	for _, share := range deal.Shares {
+		total += share.Amount
+	}
+	assert.Equal(t, 900, total)
+	amount, ok := deal.AmountFor("a")
+	assert.True(t, ok)
+	assert.Greater(t, amount, deal.Shares[1].Amount)
+
+	_, err = ProposeDeal(DealICM, players, []int{500, 300, 200}, 300)
+	assert.Error(t, err)
+	_, err = ProposeDeal("coinFlip", players, []int{500, 300, 200}, 0)
+	assert.Error(t, err)
+}
+
+func TestChipChopWithoutChips(t *testing.T) {
+	_, err := ChipChop([]int{0, 0}, []int{70, 30})
+	assert.Error(t, err)
+	_, err = ChipChop(nil, nil)
+	assert.Error(t, err)
+
+	_, err = ProposeDeal(DealChipChop, []Player{{ID: "a"}, {ID: "b"}}, []int{70, 30}, 0)
+	assert.Error(t, err, "A deal with no chips left should be rejected, not panic")
+}
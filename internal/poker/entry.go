@@ -0,0 +1,55 @@
+package poker
+
+import "fmt"
+
+// TournamentEntry is one paid entry of a player in a tournament. Re-entering
+// after busting creates a new entry; rebuys and add-ons add to the current one.
+type TournamentEntry struct {
+	WalletID uint
+	Entry    int
+	Chips    int // chips bought with this entry, rebuys and add-on included
+	Rebuys   int
+	AddOn    bool
+	Busted   bool
+}
+
+// CheckLateEntry validates registering or re-entering once the tournament is
+// running. entries is how many entries the player already has.
+func (cfg TournamentConfig) CheckLateEntry(levelIndex int, entries int) error {
+	if levelIndex+1 > cfg.LateRegistrationLevel {
+		return fmt.Errorf("late registration closed after level %d", cfg.LateRegistrationLevel)
+	}
+	if entries > cfg.MaxReEntries {
+		return fmt.Errorf("no re-entries left, the limit is %d", cfg.MaxReEntries)
+	}
+	return nil
+}
+
+// CheckRebuy validates a rebuy for a player holding chips. Rebuys are open
+// through RebuyLevels to players at or below the starting stack.
+func (cfg TournamentConfig) CheckRebuy(levelIndex int, chips int, entry TournamentEntry) error {
+	if cfg.RebuyChips <= 0 || levelIndex+1 > cfg.RebuyLevels {
+		return fmt.Errorf("rebuy period is over")
+	}
+	if cfg.MaxRebuys > 0 && entry.Rebuys >= cfg.MaxRebuys {
+		return fmt.Errorf("no rebuys left, the limit is %d", cfg.MaxRebuys)
+	}
+	if chips > cfg.StartingChips {
+		return fmt.Errorf("rebuys need a stack of %d chips or less", cfg.StartingChips)
+	}
+	return nil
+}
+
+// CheckAddOn validates the one-time add-on, which is taken during a break.
+func (cfg TournamentConfig) CheckAddOn(level BlindLevel, entry TournamentEntry) error {
+	if cfg.AddOnChips <= 0 {
+		return fmt.Errorf("tournament has no add-on")
+	}
+	if !level.Break {
+		return fmt.Errorf("add-ons are only taken during a break")
+	}
+	if entry.AddOn {
+		return fmt.Errorf("add-on already taken")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLateEntryAndRebuyRules(t *testing.T) {
+	cfg := TournamentConfig{
+		StartingChips:         1000,
+		LateRegistrationLevel: 4,
+		MaxReEntries:          1,
+		RebuyLevels:           3,
+		RebuyChips:            1000,
+		MaxRebuys:             2,
+		AddOnChips:            2000,
+	}
+
+	assert.NoError(t, cfg.CheckLateEntry(3, 0))
+	assert.NoError(t, cfg.CheckLateEntry(3, 1))
+	assert.Error(t, cfg.CheckLateEntry(3, 2))
+	assert.Error(t, cfg.CheckLateEntry(4, 0))
+
+	entry := TournamentEntry{Entry: 1}
+	assert.NoError(t, cfg.CheckRebuy(2, 1000, entry))
+	assert.Error(t, cfg.CheckRebuy(2, 1001, entry))
+	assert.Error(t, cfg.CheckRebuy(3, 0, entry))
+	entry.Rebuys = 2
+	assert.Error(t, cfg.CheckRebuy(0, 0, entry))
+
+	assert.Error(t, cfg.CheckAddOn(BlindLevel{SmallBlind: 10, BigBlind: 20}, entry))
+	assert.NoError(t, cfg.CheckAddOn(BlindLevel{Break: true}, entry))
+	entry.AddOn = true
+	assert.Error(t, cfg.CheckAddOn(BlindLevel{Break: true}, entry))
+}
@@ -0,0 +1,61 @@
+package poker
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// TableEvent is a notification about something that happened at a table
+// outside of the regular table and player updates.
+type TableEvent struct {
+	Type     string
+	TableID  string
+	PlayerID string      `json:",omitempty"`
+	Message  string      `json:",omitempty"`
+	Data     interface{} `json:",omitempty"`
+}
+
+func TableSubject(tableID string) string {
+	return fmt.Sprintf("pokerServer.tournament.%s", tableID)
+}
+
+// TournamentSubject carries the events of a whole tournament. Table IDs of a
+// tournament extend its ID, so the subjects never clash.
+func TournamentSubject(tournamentID string) string {
+	return fmt.Sprintf("pokerServer.tournament.%s", tournamentID)
+}
+
+// ActionSubject is where the player whose turn it is sends their action.
+func ActionSubject(tableID string, playerID string) string {
+	return fmt.Sprintf("pokerClient.tournament.%s.%s", tableID, playerID)
+}
+
+// DealReplySubject is where a player accepts or rejects a proposed deal.
+func DealReplySubject(tournamentID string, playerID string) string {
+	return fmt.Sprintf("pokerClient.tournament.%s.deal.%s", tournamentID, playerID)
+}
+
+// AdminSubject carries alerts for the staff, such as a table that stopped
+// because of an error.
+func AdminSubject() string {
+	return "pokerServer.admin.alerts"
+}
+
+func PlayerSubject(tableID string, playerID string) string {
+	return fmt.Sprintf("pokerServer.tournament.%s.%s", tableID, playerID)
+}
+
+func SendEventToNATS(js nats.JetStreamContext, subject string, event TableEvent, opts ...nats.PubOpt) error {
+	messageBytes, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event for table %s: %w", event.Type, event.TableID, err)
+	}
+
+	if _, err := js.Publish(subject, messageBytes, opts...); err != nil {
+		return fmt.Errorf("failed to publish %s event to JetStream on %s: %w", event.Type, subject, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package poker
+
+// Finish is the finishing position of a busted player. Players who bust in the
+// same Round with the same starting stack share a Position covering Places
+// positions.
+type Finish struct {
+	Player   Player
+	Round    int
+	Position int
+	Places   int
+}
+
+// RankRound gives finishing positions to the players busted in one round,
+// with remaining players still in the tournament. A bigger starting stack
+// finishes higher.
+func RankRound(busted []Player, round int, remaining int) []Finish {
+	players := append([]Player(nil), busted...)
+	SortEliminations(players)
+
+	finishes := make([]Finish, len(players))
+	position := remaining + 1
+	for end := len(players); end > 0; {
+		start := end - 1
+		for start > 0 && players[start-1].HandStartChips == players[end-1].HandStartChips {
+			start--
+		}
+		for i := start; i < end; i++ {
+			finishes[i] = Finish{Player: players[i], Round: round, Position: position, Places: end - start}
+		}
+		position += end - start
+		end = start
+	}
+	return finishes
+}
+
+// RerankFinishes recomputes every position from the last round back, once
+// the number of survivors is final. Positions given at bust time can move
+// while late entries still join the field.
+func RerankFinishes(finishes []Finish, survivors int) []Finish {
+	ranked := make([]Finish, 0, len(finishes))
+	remaining := survivors
+	for end := len(finishes); end > 0; {
+		start := end - 1
+		for start > 0 && finishes[start-1].Round == finishes[end-1].Round {
+			start--
+		}
+		var players []Player
+		for _, finish := range finishes[start:end] {
+			players = append(players, finish.Player)
+		}
+		ranked = append(RankRound(players, finishes[start].Round, remaining), ranked...)
+		remaining += end - start
+		end = start
+	}
+	return ranked
+}
+
+// SharedPrize returns what the index-th of the players tied on a position
+// receives: the prizes of every position they cover, split evenly, odd chips
+// to the first ones.
+func (pool PrizePool) SharedPrize(position int, places int, index int) int {
+	if places <= 1 {
+		return pool.Prize(position)
+	}
+	total := 0
+	for p := position; p < position+places; p++ {
+		total += pool.Prize(p)
+	}
+	share := total / places
+	if index < total%places {
+		share++
+	}
+	return share
+}
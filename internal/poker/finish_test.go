@@ -0,0 +1,48 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankRoundTieBreaks(t *testing.T) {
+	finishes := RankRound([]Player{
+		{ID: "a", HandStartChips: 500},
+		{ID: "b", HandStartChips: 900},
+		{ID: "c", HandStartChips: 500},
+		{ID: "d", HandStartChips: 100},
+	}, 1, 3)
+
+	positions := map[string]Finish{}
+	for _, finish := range finishes {
+		positions[finish.Player.ID] = finish
+	}
+	assert.Equal(t, 4, positions["b"].Position)
+	assert.Equal(t, 5, positions["a"].Position)
+	assert.Equal(t, 5, positions["c"].Position)
+	assert.Equal(t, 2, positions["c"].Places)
+	assert.Equal(t, 7, positions["d"].Position)
+}
+
+func TestRerankFinishes(t *testing.T) {
+	finishes := append(
+		RankRound([]Player{{ID: "first"}}, 1, 9),
+		RankRound([]Player{{ID: "second", HandStartChips: 10}, {ID: "third", HandStartChips: 20}}, 2, 7)...,
+	)
+
+	ranked := RerankFinishes(finishes, 1)
+	assert.Equal(t, "first", ranked[0].Player.ID)
+	assert.Equal(t, 4, ranked[0].Position)
+	assert.Equal(t, 3, ranked[1].Position)
+	assert.Equal(t, 2, ranked[2].Position)
+}
+
+func TestSharedPrize(t *testing.T) {
+	pool := PrizePool{Prizes: []int{500, 300, 201}}
+
+	assert.Equal(t, 500, pool.SharedPrize(1, 1, 0))
+	assert.Equal(t, 251, pool.SharedPrize(2, 2, 0))
+	assert.Equal(t, 250, pool.SharedPrize(2, 2, 1))
+	assert.Equal(t, 100, pool.SharedPrize(3, 2, 1))
+}
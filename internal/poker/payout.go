@@ -0,0 +1,130 @@
+package poker
+
+import "fmt"
+
+// PayoutTier pays the places in Percentages, in basis points of the prize
+// pool, to fields of up to MaxEntries entries. A MaxEntries of 0 matches any
+// field size.
+type PayoutTier struct {
+	MaxEntries  int
+	Percentages []int
+}
+
+// PayoutStructure is either a fixed prize per place or percentage tiers
+// picked by field size.
+type PayoutStructure struct {
+	Fixed []int
+	Tiers []PayoutTier
+}
+
+func (structure PayoutStructure) Validate() error {
+	if len(structure.Fixed) > 0 && len(structure.Tiers) > 0 {
+		return fmt.Errorf("payouts cannot be both fixed and percentage based")
+	}
+	for _, prize := range structure.Fixed {
+		if prize < 0 {
+			return fmt.Errorf("invalid fixed prize %d", prize)
+		}
+	}
+	for _, tier := range structure.Tiers {
+		total := 0
+		for _, percentage := range tier.Percentages {
+			if percentage < 0 {
+				return fmt.Errorf("invalid payout percentage %d", percentage)
+			}
+			total += percentage
+		}
+		if total != 10000 {
+			return fmt.Errorf("payouts for up to %d entries add up to %d basis points, not 10000", tier.MaxEntries, total)
+		}
+	}
+	return nil
+}
+
+// tierFor returns the percentages of the smallest tier the field fits in.
+func (structure PayoutStructure) tierFor(entries int) []int {
+	var best *PayoutTier
+	for i := range structure.Tiers {
+		tier := &structure.Tiers[i]
+		if tier.MaxEntries != 0 && tier.MaxEntries < entries {
+			continue
+		}
+		if best == nil || best.MaxEntries == 0 || (tier.MaxEntries != 0 && tier.MaxEntries < best.MaxEntries) {
+			best = tier
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.Percentages
+}
+
+// PrizePool is the money a tournament pays out and how it is split. Overlay
+// is what the house adds when the buy-ins fall short of the guarantee or of
+// the fixed prizes.
+type PrizePool struct {
+	Entries   int
+	Collected int
+	Guarantee int
+	Total     int
+	Overlay   int
+	Bounties  int   // kept out of the prizes for the bounty of every entry and rebuy
+	Prizes    []int // by finishing position
+}
+
+// Prize returns the prize for a finishing position, 0 outside the money.
+func (pool PrizePool) Prize(position int) int {
+	if position < 1 || position > len(pool.Prizes) {
+		return 0
+	}
+	return pool.Prizes[position-1]
+}
+
+// CalculatePrizePool works out the prize ladder for a field of entries, with
+// the rebuys and add-ons they bought. Rounding leftovers go to the winner.
+// Every entry and rebuy also buys a bounty, which is not part of the prizes.
+func CalculatePrizePool(cfg TournamentConfig, entries int, rebuys int, addOns int) (PrizePool, error) {
+	if err := cfg.Payouts.Validate(); err != nil {
+		return PrizePool{}, err
+	}
+	if cfg.Bounty < 0 || cfg.Bounty > cfg.BuyIn {
+		return PrizePool{}, fmt.Errorf("bounty %d does not fit in a buy-in of %d", cfg.Bounty, cfg.BuyIn)
+	}
+	if rebuys > 0 && cfg.Bounty > cfg.RebuyCost {
+		return PrizePool{}, fmt.Errorf("bounty %d does not fit in a rebuy of %d", cfg.Bounty, cfg.RebuyCost)
+	}
+
+	pool := PrizePool{
+		Entries:   entries,
+		Collected: entries*(cfg.BuyIn-cfg.Bounty) + rebuys*(cfg.RebuyCost-cfg.Bounty) + addOns*cfg.AddOnCost,
+		Guarantee: cfg.Guarantee,
+		Bounties:  (entries + rebuys) * cfg.Bounty,
+	}
+
+	if len(cfg.Payouts.Fixed) > 0 {
+		pool.Prizes = append([]int(nil), cfg.Payouts.Fixed...)
+		for _, prize := range pool.Prizes {
+			pool.Total += prize
+		}
+		pool.Overlay = max(pool.Total-pool.Collected, 0)
+		return pool, nil
+	}
+
+	pool.Total = max(pool.Collected, pool.Guarantee)
+	pool.Overlay = pool.Total - pool.Collected
+
+	percentages := cfg.Payouts.tierFor(entries)
+	paid := 0
+	for place, percentage := range percentages {
+		if place >= entries {
+			break
+		}
+		prize := pool.Total * percentage / 10000
+		pool.Prizes = append(pool.Prizes, prize)
+		paid += prize
+	}
+	if len(pool.Prizes) > 0 {
+		pool.Prizes[0] += pool.Total - paid
+	}
+	return pool, nil
+}
@@ -0,0 +1,70 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentagePrizePool(t *testing.T) {
+	cfg := TournamentConfig{
+		BuyIn: 100,
+		Payouts: PayoutStructure{Tiers: []PayoutTier{
+			{MaxEntries: 9, Percentages: []int{6500, 3500}},
+			{MaxEntries: 0, Percentages: []int{5000, 3000, 2000}},
+		}},
+	}
+
+	pool, err := CalculatePrizePool(cfg, 7, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 700, pool.Total)
+	assert.Equal(t, []int{455, 245}, pool.Prizes)
+
+	pool, err = CalculatePrizePool(cfg, 13, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{650, 390, 260}, pool.Prizes)
+	assert.Equal(t, 0, pool.Prize(4))
+
+	cfg.Guarantee = 2000
+	pool, err = CalculatePrizePool(cfg, 13, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2000, pool.Total)
+	assert.Equal(t, 700, pool.Overlay)
+}
+
+func TestFixedPrizePoolAndRounding(t *testing.T) {
+	pool, err := CalculatePrizePool(TournamentConfig{BuyIn: 10, Payouts: PayoutStructure{Fixed: []int{500, 200}}}, 20, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 700, pool.Total)
+	assert.Equal(t, 500, pool.Overlay)
+
+	pool, err = CalculatePrizePool(TournamentConfig{BuyIn: 10, Payouts: PayoutStructure{Tiers: []PayoutTier{{Percentages: []int{3333, 3333, 3334}}}}}, 10, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{34, 33, 33}, pool.Prizes)
+
+	_, err = CalculatePrizePool(TournamentConfig{Payouts: PayoutStructure{Tiers: []PayoutTier{{Percentages: []int{5000}}}}}, 10, 0, 0)
+	assert.Error(t, err)
+}
+
+func TestPrizePoolCountsRebuysAndAddOns(t *testing.T) {
+	cfg := TournamentConfig{BuyIn: 100, RebuyCost: 100, AddOnCost: 50, Payouts: PayoutStructure{Tiers: []PayoutTier{{Percentages: []int{10000}}}}}
+
+	pool, err := CalculatePrizePool(cfg, 10, 4, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, 1700, pool.Collected)
+	assert.Equal(t, []int{1700}, pool.Prizes)
+}
+
+func TestPrizePoolKeepsBountiesOfRebuys(t *testing.T) {
+	cfg := TournamentConfig{BuyIn: 100, Bounty: 40, RebuyCost: 100, Payouts: PayoutStructure{Tiers: []PayoutTier{{Percentages: []int{10000}}}}}
+
+	pool, err := CalculatePrizePool(cfg, 10, 4, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 840, pool.Collected)
+	assert.Equal(t, 560, pool.Bounties)
+	assert.Equal(t, 1400, pool.Collected+pool.Bounties, "Every buy-in and rebuy should be accounted for")
+
+	cfg.RebuyCost = 30
+	_, err = CalculatePrizePool(cfg, 10, 4, 0)
+	assert.Error(t, err)
+}
@@ -9,6 +9,9 @@ import (
 
 type Player struct {
 	ID               string
+	WalletID         uint
+	Entry            int
+	Seat             int
 	Chips            int
 	Cards            []Card
 	LastAction       string
@@ -19,18 +22,31 @@ type Player struct {
 	PreAction        []string
 	LastBet          int
 	TotalBet         int
+	DeadBet          int
 	IsAFK            bool
 	CallAmount       int
 	HasFold          bool
 	HasAllIn         bool
 	IsEliminated     bool
+	HandStartChips   int // stack before blinds and antes of the current hand
+	Bounty           int // on this player's head
+	BountiesWon      int
+	SittingOut       bool
+	WaitForBB        bool
+	PostBlind        bool
 	HandStrength     int
 	BestHand         []Card
 	HandDescription  string
 	HandScore        int
 }
 
-func SendPlayerUpdateToNATS(js nats.JetStreamContext, tableID string, player Player) error {
+// Committed returns every chip the player put into the pot this hand,
+// including antes.
+func (player *Player) Committed() int {
+	return player.TotalBet + player.DeadBet
+}
+
+func SendPlayerUpdateToNATS(js nats.JetStreamContext, tableID string, player Player, opts ...nats.PubOpt) error {
 	subject := fmt.Sprintf("pokerServer.tournament.%s.%s", tableID, player.ID)
 
 	messageBytes, err := json.Marshal(player)
@@ -38,7 +54,7 @@ func SendPlayerUpdateToNATS(js nats.JetStreamContext, tableID string, player Pla
 		return fmt.Errorf("failed to marshal player data for player %s: %w", player.ID, err)
 	}
 
-	if _, err := js.Publish(subject, messageBytes); err != nil {
+	if _, err := js.Publish(subject, messageBytes, opts...); err != nil {
 		return fmt.Errorf("failed to publish message to JetStream for player %s: %w", player.ID, err)
 	}
 
@@ -0,0 +1,148 @@
+package poker
+
+import "sort"
+
+// Pot is the main pot or a side pot of a hand. Payouts holds what each winner
+// received after the rake was taken.
+type Pot struct {
+	Amount   int
+	Rake     int
+	Eligible []string
+	Winners  []string
+	Payouts  map[string]int
+}
+
+// ReturnUncalledBet gives back the part of the largest bet that nobody
+// matched, so it is neither raked nor counted as won.
+func (table *Table) ReturnUncalledBet() {
+	var top *Player
+	highest, second := 0, 0
+	for _, player := range table.DealtPlayers() {
+		if player.Committed() > highest {
+			second = highest
+			highest = player.Committed()
+			top = player
+		} else if player.Committed() > second {
+			second = player.Committed()
+		}
+	}
+	if top == nil || highest == second {
+		return
+	}
+
+	uncalled := min(highest-second, top.TotalBet)
+	top.TotalBet -= uncalled
+	top.Chips += uncalled
+	table.TotalBet -= uncalled
+}
+
+// BuildPots splits the chips committed this hand into a main pot and one side
+// pot for every all-in level. Chips from folded players stay in the pots they
+// reached but those players are never eligible to win them.
+func (table *Table) BuildPots() []Pot {
+	players := table.DealtPlayers()
+
+	levels := []int{}
+	for _, player := range players {
+		if !player.HasFold && player.Committed() > 0 && !containsInt(levels, player.Committed()) {
+			levels = append(levels, player.Committed())
+		}
+	}
+	if len(levels) == 0 {
+		levels = append(levels, 0)
+	}
+	sort.Ints(levels)
+
+	pots := []Pot{}
+	previous := 0
+	for _, level := range levels {
+		pot := Pot{}
+		for _, player := range players {
+			committed := player.Committed()
+			pot.Amount += min(committed, level) - min(committed, previous)
+			if !player.HasFold && committed >= level {
+				pot.Eligible = append(pot.Eligible, player.ID)
+			}
+		}
+		pots = append(pots, pot)
+		previous = level
+	}
+
+	for _, player := range players {
+		if player.Committed() > previous {
+			pots[len(pots)-1].Amount += player.Committed() - previous
+		}
+	}
+
+	return pots
+}
+
+// SettlePots builds the pots for the hand, takes the rake on cash tables and
+// pays every pot to the best eligible hands, then the bounties of the players
+// knocked out. EvaluateHand must have run first
+// when more than one player reached showdown.
+func (table *Table) SettlePots() {
+	table.ReturnUncalledBet()
+	pots := table.BuildPots()
+
+	if table.GameType == GameCash {
+		table.Rake = table.Cash.Rake.Apply(pots, len(table.DealtPlayers()), len(table.FlopCards) > 0)
+	}
+
+	for i := range pots {
+		table.awardPot(&pots[i])
+	}
+
+	table.Pots = pots
+	table.TotalBet = 0
+	table.AwardBounties()
+}
+
+// awardPot splits the pot between the eligible players with the best hand.
+// Odd chips go to the winners closest to the left of the button.
+func (table *Table) awardPot(pot *Pot) {
+	winners := []*Player{}
+	best := 0
+	for _, id := range pot.Eligible {
+		player := table.FindPlayer(id)
+		switch {
+		case len(winners) == 0 || player.HandScore < best:
+			winners = []*Player{player}
+			best = player.HandScore
+		case player.HandScore == best:
+			winners = append(winners, player)
+		}
+	}
+	if len(winners) == 0 {
+		return
+	}
+
+	seats := len(table.Seats)
+	sort.SliceStable(winners, func(i, j int) bool {
+		return (winners[i].Seat-table.SBSeat+seats)%seats < (winners[j].Seat-table.SBSeat+seats)%seats
+	})
+
+	prize := pot.Amount - pot.Rake
+	share := prize / len(winners)
+	oddChips := prize % len(winners)
+
+	pot.Payouts = make(map[string]int)
+	for i, player := range winners {
+		amount := share
+		if i < oddChips {
+			amount++
+		}
+		player.Chips += amount
+		pot.Winners = append(pot.Winners, player.ID)
+		pot.Payouts[player.ID] = amount
+	}
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
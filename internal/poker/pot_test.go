@@ -0,0 +1,80 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSidePots(t *testing.T) {
+	table := newTestTable(t,
+		Player{ID: "short", TotalBet: 100, HasAllIn: true},
+		Player{ID: "medium", TotalBet: 300, HasAllIn: true},
+		Player{ID: "big", TotalBet: 500},
+		Player{ID: "folded", TotalBet: 50, HasFold: true},
+	)
+
+	pots := table.BuildPots()
+
+	assert.Len(t, pots, 3)
+	assert.Equal(t, 350, pots[0].Amount)
+	assert.Equal(t, []string{"short", "medium", "big"}, pots[0].Eligible)
+	assert.Equal(t, 400, pots[1].Amount)
+	assert.Equal(t, []string{"medium", "big"}, pots[1].Eligible)
+	assert.Equal(t, 200, pots[2].Amount)
+	assert.Equal(t, []string{"big"}, pots[2].Eligible)
+}
+
+func TestSettlePotsWithRake(t *testing.T) {
+	table := newTestCashTable(t)
+	table.Cash.Rake = RakeConfig{
+		Rate:         500,
+		Cap:          10,
+		Caps:         []RakeCap{{Players: 3, Cap: 15}},
+		NoFlopNoDrop: true,
+	}
+	for i, player := range []Player{
+		{ID: "short", TotalBet: 100, HasAllIn: true, HandScore: 10},
+		{ID: "medium", TotalBet: 300, HasAllIn: true, HandScore: 20},
+		{ID: "big", Chips: 100, TotalBet: 300, HandScore: 30},
+	} {
+		assert.NoError(t, table.SitPlayer(i+1, player))
+	}
+	table.SBSeat = 1
+	table.FlopCards = []Card{{Suit: Hearts, Value: Two}, {Suit: Hearts, Value: Three}, {Suit: Hearts, Value: Four}}
+
+	table.SettlePots()
+
+	assert.Equal(t, 15, table.Rake, "Rake should stop at the three-handed cap")
+	assert.Equal(t, 15, table.Pots[0].Rake)
+	assert.Equal(t, 0, table.Pots[1].Rake)
+	assert.Equal(t, 285, table.FindPlayer("short").Chips)
+	assert.Equal(t, 400, table.FindPlayer("medium").Chips)
+	assert.Equal(t, 100, table.FindPlayer("big").Chips)
+}
+
+func TestNoFlopNoDrop(t *testing.T) {
+	table := newTestCashTable(t)
+	table.Cash.Rake.NoFlopNoDrop = true
+	assert.NoError(t, table.SitPlayer(1, Player{ID: "player1", TotalBet: 20}))
+	assert.NoError(t, table.SitPlayer(2, Player{ID: "player2", TotalBet: 10, HasFold: true}))
+
+	table.SettlePots()
+
+	assert.Equal(t, 0, table.Rake)
+	assert.Equal(t, 30, table.FindPlayer("player1").Chips, "Uncalled bet should be returned with the pot")
+}
+
+func TestSplitPotOddChip(t *testing.T) {
+	table := newTestTable(t,
+		Player{ID: "player1", TotalBet: 101, HandScore: 5},
+		Player{ID: "player2", TotalBet: 101, HandScore: 5},
+		Player{ID: "player3", TotalBet: 101, HandScore: 9},
+	)
+	table.SBSeat = 2
+
+	table.SettlePots()
+
+	assert.Equal(t, 152, table.FindPlayer("player2").Chips, "Odd chip goes to the first winner after the button")
+	assert.Equal(t, 151, table.FindPlayer("player1").Chips)
+}
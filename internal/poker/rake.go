@@ -0,0 +1,63 @@
+package poker
+
+import "fmt"
+
+type RakeConfig struct {
+	Rate         int // basis points, 500 = 5%
+	Cap          int // used when no entry in Caps applies, 0 means uncapped
+	Caps         []RakeCap
+	NoFlopNoDrop bool
+}
+
+// RakeCap limits the rake of hands dealt to at least Players players.
+type RakeCap struct {
+	Players int
+	Cap     int
+}
+
+func (cfg RakeConfig) Validate() error {
+	if cfg.Rate < 0 || cfg.Rate > 10000 || cfg.Cap < 0 {
+		return fmt.Errorf("invalid rake %d bp capped at %d", cfg.Rate, cfg.Cap)
+	}
+	for _, c := range cfg.Caps {
+		if c.Players < MinTableSize || c.Cap < 0 {
+			return fmt.Errorf("invalid rake cap %d for %d players", c.Cap, c.Players)
+		}
+	}
+	return nil
+}
+
+// CapFor returns the rake cap for a hand dealt to the given number of
+// players, picking the entry with the most players that still applies.
+func (cfg RakeConfig) CapFor(players int) int {
+	limit := cfg.Cap
+	matched := 0
+	for _, c := range cfg.Caps {
+		if c.Players <= players && c.Players > matched {
+			limit = c.Cap
+			matched = c.Players
+		}
+	}
+	return limit
+}
+
+// Apply sets the rake of every pot and returns the total. Each pot is raked
+// on its own and rounded down to whole chips; once the cap is reached the
+// remaining pots are not raked.
+func (cfg RakeConfig) Apply(pots []Pot, players int, sawFlop bool) int {
+	if cfg.Rate == 0 || (cfg.NoFlopNoDrop && !sawFlop) {
+		return 0
+	}
+
+	limit := cfg.CapFor(players)
+	total := 0
+	for i := range pots {
+		rake := pots[i].Amount * cfg.Rate / 10000
+		if limit > 0 && total+rake > limit {
+			rake = limit - total
+		}
+		pots[i].Rake = rake
+		total += rake
+	}
+	return total
+}
@@ -0,0 +1,215 @@
+package poker
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	MinTableSize = 2
+	MaxTableSize = 10
+)
+
+// Seat is a fixed position at the table. Number is 1-based and never changes
+// while the table exists; an empty seat has a nil Player. An empty seat can be
+// held for a player coming from a waiting list.
+type Seat struct {
+	Number      int
+	Player      *Player
+	ReservedFor string
+}
+
+func (seat Seat) IsEmpty() bool {
+	return seat.Player == nil
+}
+
+func ValidTableSize(size int) bool {
+	return size >= MinTableSize && size <= MaxTableSize
+}
+
+// NewTable creates a table with maxSeats empty seats.
+func NewTable(id string, maxSeats int) (Table, error) {
+	if !ValidTableSize(maxSeats) {
+		return Table{}, fmt.Errorf("invalid table size %d, must be between %d and %d", maxSeats, MinTableSize, MaxTableSize)
+	}
+
+	table := Table{
+		ID:                 id,
+		Seats:              make([]Seat, maxSeats),
+		FlopCards:          []Card{},
+		TotalBetIndividual: make(map[string]int),
+	}
+	for i := range table.Seats {
+		table.Seats[i].Number = i + 1
+	}
+
+	return table, nil
+}
+
+// SitPlayer puts player in the given seat number.
+func (table *Table) SitPlayer(seatNumber int, player Player) error {
+	if seatNumber < 1 || seatNumber > len(table.Seats) {
+		return fmt.Errorf("seat %d does not exist on table %s", seatNumber, table.ID)
+	}
+	if table.FindPlayer(player.ID) != nil {
+		return fmt.Errorf("player %s is already seated on table %s", player.ID, table.ID)
+	}
+	seat := &table.Seats[seatNumber-1]
+	if !seat.IsEmpty() {
+		return fmt.Errorf("seat %d on table %s is taken by %s", seatNumber, table.ID, seat.Player.ID)
+	}
+
+	player.Seat = seat.Number
+	seat.Player = &player
+	seat.ReservedFor = ""
+	return nil
+}
+
+// ReserveSeat holds an empty seat for playerID. An empty playerID releases it.
+func (table *Table) ReserveSeat(seatNumber int, playerID string) error {
+	if seatNumber < 1 || seatNumber > len(table.Seats) {
+		return fmt.Errorf("seat %d does not exist on table %s", seatNumber, table.ID)
+	}
+	seat := &table.Seats[seatNumber-1]
+	if !seat.IsEmpty() {
+		return fmt.Errorf("seat %d on table %s is taken by %s", seatNumber, table.ID, seat.Player.ID)
+	}
+	seat.ReservedFor = playerID
+	return nil
+}
+
+// SitPlayerAnywhere puts player in the first open seat and returns its number.
+func (table *Table) SitPlayerAnywhere(player Player) (int, error) {
+	empty := table.OpenSeats()
+	if len(empty) == 0 {
+		return 0, fmt.Errorf("table %s is full", table.ID)
+	}
+	if err := table.SitPlayer(empty[0], player); err != nil {
+		return 0, err
+	}
+	return empty[0], nil
+}
+
+// StandUp empties the seat held by playerID and returns the player that was
+// sitting there.
+func (table *Table) StandUp(playerID string) (Player, bool) {
+	index := table.SeatIndexOf(playerID)
+	if index == -1 {
+		return Player{}, false
+	}
+	player := *table.Seats[index].Player
+	table.Seats[index].Player = nil
+	return player, true
+}
+
+// PlayerAt returns the player sitting in seatNumber, or nil if it is empty.
+func (table *Table) PlayerAt(seatNumber int) *Player {
+	if seatNumber < 1 || seatNumber > len(table.Seats) {
+		return nil
+	}
+	return table.Seats[seatNumber-1].Player
+}
+
+func (table *Table) FindPlayer(playerID string) *Player {
+	index := table.SeatIndexOf(playerID)
+	if index == -1 {
+		return nil
+	}
+	return table.Seats[index].Player
+}
+
+// SeatIndexOf returns the index in Seats of the seat held by playerID, or -1.
+func (table *Table) SeatIndexOf(playerID string) int {
+	for i, seat := range table.Seats {
+		if !seat.IsEmpty() && seat.Player.ID == playerID {
+			return i
+		}
+	}
+	return -1
+}
+
+// UnmarshalJSON also reads tables of the first revision, which listed their
+// players in Players instead of seating them. Each player gets the seat of
+// their position in the list.
+func (table *Table) UnmarshalJSON(data []byte) error {
+	type plain Table
+	var decoded struct {
+		plain
+		Players []Player
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*table = Table(decoded.plain)
+	if len(table.Seats) > 0 || len(decoded.Players) == 0 {
+		return nil
+	}
+	table.Seats = make([]Seat, len(decoded.Players))
+	for i := range decoded.Players {
+		player := decoded.Players[i]
+		player.Seat = i + 1
+		table.Seats[i] = Seat{Number: i + 1, Player: &player}
+	}
+	return nil
+}
+
+// SeatedPlayers returns the players at the table in seat order.
+func (table *Table) SeatedPlayers() []*Player {
+	players := []*Player{}
+	for i := range table.Seats {
+		if !table.Seats[i].IsEmpty() {
+			players = append(players, table.Seats[i].Player)
+		}
+	}
+	return players
+}
+
+// DealtPlayers returns the seated players that take part in hands, skipping
+// those sitting out.
+func (table *Table) DealtPlayers() []*Player {
+	players := []*Player{}
+	for _, player := range table.SeatedPlayers() {
+		if !player.SittingOut {
+			players = append(players, player)
+		}
+	}
+	return players
+}
+
+func (table *Table) PlayerCount() int {
+	return len(table.SeatedPlayers())
+}
+
+// OpenSeats returns the empty seats that are not reserved for anyone.
+func (table *Table) OpenSeats() []int {
+	open := []int{}
+	for _, seat := range table.Seats {
+		if seat.IsEmpty() && seat.ReservedFor == "" {
+			open = append(open, seat.Number)
+		}
+	}
+	return open
+}
+
+func (table *Table) EmptySeats() []int {
+	empty := []int{}
+	for _, seat := range table.Seats {
+		if seat.IsEmpty() {
+			empty = append(empty, seat.Number)
+		}
+	}
+	return empty
+}
+
+// nextSeatIndex walks the seats clockwise from startIndex and returns the
+// index of the first occupied seat whose player matches, or -1.
+func (table *Table) nextSeatIndex(startIndex int, match func(*Player) bool) int {
+	for i := 1; i <= len(table.Seats); i++ {
+		currentIndex := (startIndex + i) % len(table.Seats)
+		player := table.Seats[currentIndex].Player
+		if player != nil && match(player) {
+			return currentIndex
+		}
+	}
+	return -1
+}
@@ -22,11 +22,17 @@ type PlayerCards struct {
 
 type Table struct {
 	ID                 string
+	TournamentID       string
+	Number             int
+	ProgressiveBounty  bool
 	CurrentBB          string
 	CurrentSB          string
+	SBSeat             int
 	CurrentTurn        string
 	NextTurn           string
 	LastAction         string
+	GameType           string // "tournament", "cash"
+	Cash               CashConfig
 	TotalBetIndividual map[string]int
 	Total              int
 	TotalBet           int
@@ -37,17 +43,32 @@ type Table struct {
 	FlopCards          []Card
 	TurnCard           *Card
 	RiverCard          *Card
-	Players            []Player
+	Seats              []Seat
 	Winners            []Player
 	BiggestBet         int
 	IsPreFlop          bool
 	Round              int
 	RoundFinish        bool
 	PreviousStage      string
+	SBValue            int
 	BBValue            int
+	AnteValue          int
+	BlindLevel         int
 	AllFoldExceptOne   bool
 	PlayerActedInRound int
 	LastToRaiserIndex  int
+	MinRaise           int // smallest raise over the call in the betting round
+	RoundStartIndex    int
+	TurnIndex          int
+	VoidReason         string
+	PausedReason       string // set while the table deals no new hands
+	TerminateReason    string // set once the table is to close
+	MoveToShard        string // set while the table waits to move to another shard
+	HandNumber         int
+	Pots               []Pot
+	History            []HandAction
+	Rake               int
+	KeepBlinds         bool
 }
 
 const (
@@ -78,12 +99,23 @@ const (
 var Values = []string{Two, Three, Four, Five, Six, Seven, Eight, Nine, Ten, Jack, Queen, King, Ace}
 
 func (table *Table) DealCards() {
+	table.DealFrom(ShuffledDeck())
+}
+
+// ShuffledDeck returns a new deck in random order.
+func ShuffledDeck() []Card {
 	deck := createDeck()
 	rand.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+	return deck
+}
+
+// DealFrom deals the hole cards and the board from deck, top card first. The
+// same deck always deals the same hand.
+func (table *Table) DealFrom(deck []Card) {
+	deck = append([]Card(nil), deck...)
 
 	// Deal 2 cards to each player
-	for i := range table.Players {
-		player := &table.Players[i]
+	for _, player := range table.DealtPlayers() {
 		if len(deck) >= 2 {
 			player.Cards = []Card{deck[0], deck[1]}
 			deck = deck[2:]
@@ -126,7 +158,7 @@ func createDeck() []Card {
 	return deck
 }
 
-func SendPTableUpdateToNATS(js nats.JetStreamContext, table *Table) error {
+func SendPTableUpdateToNATS(js nats.JetStreamContext, table *Table, opts ...nats.PubOpt) error {
 	subject := fmt.Sprintf("pokerServer.tournament.%s", table.ID)
 
 	messageBytes, err := json.Marshal(table)
@@ -134,15 +166,15 @@ func SendPTableUpdateToNATS(js nats.JetStreamContext, table *Table) error {
 		return fmt.Errorf("failed to marshal player data for player %s: %w", table.ID, err)
 	}
 
-	if _, err := js.Publish(subject, messageBytes); err != nil {
+	if _, err := js.Publish(subject, messageBytes, opts...); err != nil {
 		return fmt.Errorf("failed to publish message to JetStream for player %s: %w", table.ID, err)
 	}
 
 	return nil
 }
 
-func (table *Table) SetTablePlayerActions(indexValue int) {
-	player := &table.Players[indexValue]
+func (table *Table) SetTablePlayerActions(seatIndex int) {
+	player := table.Seats[seatIndex].Player
 
 	player.AvailableActions = []string{}
 
@@ -163,61 +195,81 @@ func (table *Table) SetTablePlayerActions(indexValue int) {
 	}
 
 	player.AvailableActions = append(player.AvailableActions, "fold")
-
-	table.Players[indexValue].AvailableActions = player.AvailableActions
 }
 
 func (table *Table) SetTablePlayersCallAmount() {
-	for i := range table.Players {
-		player := &table.Players[i]
+	for _, player := range table.DealtPlayers() {
 		if !player.HasFold && !player.HasAllIn && !player.IsEliminated {
-			player.CallAmount = table.BiggestBet + player.TotalBet
+			player.CallAmount = table.BiggestBet - player.TotalBet
 		}
 	}
 }
 
 func (table *Table) SetSMBB() {
+	table.PostAntes()
+
 	var smPlayer, bbPlayer *Player
 	bbBet := table.BBValue
 	smBet := table.BBValue / 2
-	for i := range table.Players {
-		player := &table.Players[i]
+	if table.SBValue > 0 {
+		smBet = table.SBValue
+	}
+	for _, player := range table.DealtPlayers() {
 		if player.ID == table.CurrentSB {
-			if table.Players[i].Chips <= smBet {
+			if player.Chips <= smBet {
 				smPlayer = player
-				table.Players[i].LastAction = "SB"
-				table.Players[i].IsSB = true
-				table.Players[i].TotalBet += table.Players[i].Chips
-				table.Players[i].HasAllIn = true
-				table.Players[i].Chips = 0
+				player.LastAction = "SB"
+				player.IsSB = true
+				player.TotalBet += player.Chips
+				player.HasAllIn = true
+				player.Chips = 0
 			} else {
 				smPlayer = player
-				table.Players[i].Chips -= smBet
-				table.Players[i].TotalBet += smBet
-				table.Players[i].LastAction = "SB"
-				table.Players[i].IsSB = true
+				player.Chips -= smBet
+				player.TotalBet += smBet
+				player.LastAction = "SB"
+				player.IsSB = true
 				table.TotalBet += smBet
 			}
 		} else if player.ID == table.CurrentBB {
-			if table.Players[i].Chips <= bbBet {
+			if player.Chips <= bbBet {
 				bbPlayer = player
-				table.Players[i].LastAction = "BB"
-				table.Players[i].IsBB = true
-				table.Players[i].TotalBet += table.Players[i].Chips
-				table.Players[i].HasAllIn = true
-				table.Players[i].Chips = 0
+				player.LastAction = "BB"
+				player.IsBB = true
+				player.TotalBet += player.Chips
+				player.HasAllIn = true
+				player.Chips = 0
 			} else {
 				bbPlayer = player
-				table.Players[i].Chips -= bbBet
-				table.Players[i].TotalBet += bbBet
-				table.Players[i].LastAction = "BB"
-				table.Players[i].IsBB = true
+				player.Chips -= bbBet
+				player.TotalBet += bbBet
+				player.LastAction = "BB"
+				player.IsBB = true
 				table.BiggestBet = bbBet
 				table.TotalBet += bbBet
 			}
 		}
 	}
 
+	for _, player := range table.DealtPlayers() {
+		if !player.PostBlind {
+			continue
+		}
+		player.PostBlind = false
+		if player.ID == table.CurrentSB || player.ID == table.CurrentBB {
+			continue
+		}
+		post := bbBet
+		if player.Chips <= post {
+			post = player.Chips
+			player.HasAllIn = true
+		}
+		player.Chips -= post
+		player.TotalBet += post
+		player.LastAction = "post"
+		table.TotalBet += post
+	}
+
 	if smPlayer == nil || bbPlayer == nil {
 		return
 	}
@@ -225,12 +277,29 @@ func (table *Table) SetSMBB() {
 	table.SetTablePlayersCallAmount()
 }
 
+// PostAntes takes the ante from every player dealt in. Antes are dead money:
+// they go into the pot but do not count towards calling a bet.
+func (table *Table) PostAntes() {
+	if table.AnteValue <= 0 {
+		return
+	}
+	for _, player := range table.DealtPlayers() {
+		ante := min(table.AnteValue, player.Chips)
+		player.Chips -= ante
+		player.DeadBet += ante
+		table.TotalBet += ante
+		if player.Chips == 0 {
+			player.HasAllIn = true
+		}
+	}
+}
+
 func (table *Table) AllPlayersExceptOneFold() {
 	activePlayers := []Player{}
 
-	for _, player := range table.Players {
+	for _, player := range table.DealtPlayers() {
 		if !player.HasFold && !player.IsEliminated {
-			activePlayers = append(activePlayers, player)
+			activePlayers = append(activePlayers, *player)
 		}
 	}
 
@@ -246,7 +315,7 @@ func (table *Table) AllPlayersExceptOneFold() {
 }
 
 func (table *Table) AllPlayersAllInExceptFolded() bool {
-	for _, player := range table.Players {
+	for _, player := range table.DealtPlayers() {
 		if player.HasFold || player.IsEliminated {
 			continue
 		}
@@ -260,7 +329,7 @@ func (table *Table) AllPlayersAllInExceptFolded() bool {
 func (table *Table) AllPlayersAllInExceptOneAndFolded() bool {
 	countNotAllIn := 0
 
-	for _, player := range table.Players {
+	for _, player := range table.DealtPlayers() {
 		if player.HasFold || player.IsEliminated {
 			continue
 		}
@@ -275,13 +344,14 @@ func (table *Table) AllPlayersAllInExceptOneAndFolded() bool {
 }
 
 func (table *Table) ClearPlayerActions() {
-	for i := range table.Players {
-		table.Players[i].CallAmount = 0
-		table.Players[i].TotalBet = 0
-		table.Players[i].LastAction = ""
-		table.Players[i].HasFold = false
-		table.Players[i].HasAllIn = false
-		table.Players[i].Cards = nil
+	for _, player := range table.SeatedPlayers() {
+		player.CallAmount = 0
+		player.TotalBet = 0
+		player.DeadBet = 0
+		player.LastAction = ""
+		player.HasFold = false
+		player.HasAllIn = false
+		player.Cards = nil
 	}
 }
 
@@ -299,9 +369,49 @@ func (table *Table) ClearTableActions() {
 	table.RiverCard = nil
 }
 
+// VoidHand cancels the hand in progress and refunds every player's TotalBet,
+// restoring stacks to their pre-hand values. The blinds stay where they are so
+// the next hand is dealt with the same button position.
+func (table *Table) VoidHand(reason string) {
+	for _, player := range table.SeatedPlayers() {
+		player.Chips += player.Committed()
+	}
+	table.Winners = nil
+	table.ClearPlayerActions()
+	table.ClearTableActions()
+	table.CurrentStage = "voided"
+	table.VoidReason = reason
+	table.KeepBlinds = true
+}
+
+// ValidDeal reports whether every player holds two cards, the board is
+// complete and no card was dealt twice.
+func (table *Table) ValidDeal() bool {
+	seen := make(map[Card]bool)
+	dealt := func(cards ...Card) bool {
+		for _, card := range cards {
+			if seen[card] {
+				return false
+			}
+			seen[card] = true
+		}
+		return true
+	}
+
+	for _, player := range table.DealtPlayers() {
+		if len(player.Cards) != 2 || !dealt(player.Cards...) {
+			return false
+		}
+	}
+	if len(table.FlopCards) != 3 || table.TurnCard == nil || table.RiverCard == nil {
+		return false
+	}
+	return dealt(table.CommunityCards()...)
+}
+
 func (table *Table) CountActivePlayers() int {
 	count := 0
-	for _, player := range table.Players {
+	for _, player := range table.DealtPlayers() {
 		if !player.HasFold && !player.HasAllIn && !player.IsEliminated {
 			count++
 		}
@@ -310,7 +420,7 @@ func (table *Table) CountActivePlayers() int {
 }
 
 func (table *Table) AllPlayersHaveCalled() bool {
-	for _, player := range table.Players {
+	for _, player := range table.DealtPlayers() {
 		// Ignorar jugadores que han hecho fold, están en all-in o están eliminados
 		if player.HasFold || player.HasAllIn || player.IsEliminated {
 			continue
@@ -340,12 +450,9 @@ func (table *Table) AssignChipsToWinner(winner *Player) {
 		return
 	}
 
-	for i := range table.Players {
-		if table.Players[i].ID == winner.ID {
-			table.Players[i].Chips += table.TotalBet
-			table.TotalBet = 0
-			return
-		}
+	if player := table.FindPlayer(winner.ID); player != nil {
+		player.Chips += table.TotalBet
+		table.TotalBet = 0
 	}
 }
 
@@ -360,11 +467,11 @@ func (table *Table) EvaluateHand() {
 	bestHandScore := 99999
 	table.CurrentStage = "showDown"
 
-	for i, player := range table.Players {
+	for _, player := range table.DealtPlayers() {
 		allCards := append(table.CommunityCards(), player.Cards...)
 		riverboatCards := make([]eval.Card, len(allCards))
 		if player.HasFold {
-			table.Players[i].Cards = nil
+			player.Cards = nil
 			continue
 		}
 		for i, card := range allCards {
@@ -405,16 +512,12 @@ func (table *Table) EvaluateHand() {
 
 		//winningHand := convertEvalCardsToCards(bestFiveOfSevenCards)
 
-		for i := range table.Players {
-			if table.Players[i].ID == player.ID {
-				table.Players[i].HandScore = handScore
-				if table.Players[i].HandScore < bestHandScore {
-					table.Winners = nil //rework para los side pots en un futuro
-					table.Winners = append(table.Winners, table.Players[i])
-					bestHandScore = table.Players[i].HandScore
-					winner = table.Players[i]
-				}
-			}
+		player.HandScore = handScore
+		if player.HandScore < bestHandScore {
+			table.Winners = nil //rework para los side pots en un futuro
+			table.Winners = append(table.Winners, *player)
+			bestHandScore = player.HandScore
+			winner = *player
 		}
 	}
 	fmt.Println("el ganador es", winner)
@@ -467,14 +570,13 @@ func convertCardToEvalCard(card Card) eval.Card {
 
 func (table *Table) AssignPlayerCardsFromSecTable(secTable *Table) {
 	playerCardsMap := make(map[string][]Card)
-	for _, player := range secTable.Players {
+	for _, player := range secTable.SeatedPlayers() {
 		if !player.HasFold {
 			playerCardsMap[player.ID] = player.Cards
 		}
 	}
 
-	for i := range table.Players {
-		player := &table.Players[i]
+	for _, player := range table.DealtPlayers() {
 		if cards, ok := playerCardsMap[player.ID]; ok {
 			player.Cards = cards
 		}
@@ -506,68 +608,86 @@ func convertEvalCardsToCards(evalCards []eval.Card) []Card {
 }
 
 func (table *Table) SMBBTurn() {
+	if table.KeepBlinds {
+		table.KeepBlinds = false
+		return
+	}
+
 	if table.CurrentSB == "" && table.CurrentBB == "" {
-		activePlayers := []Player{}
-		for _, player := range table.Players {
-			if !player.IsEliminated {
+		activePlayers := []*Player{}
+		for _, player := range table.SeatedPlayers() {
+			if !player.IsEliminated && !player.SittingOut {
 				activePlayers = append(activePlayers, player)
 			}
 		}
 		if len(activePlayers) >= 2 {
 			table.CurrentSB = activePlayers[0].ID
 			table.CurrentBB = activePlayers[1].ID
+			table.SBSeat = activePlayers[0].Seat
 		}
 		return
 	}
 
-	sbIndex := -1
-	for i, player := range table.Players {
-		if player.ID == table.CurrentSB {
-			sbIndex = i
-			break
-		}
-	}
-
+	// If the small blind left the table, rotate from the seat they vacated.
+	sbIndex := table.SeatIndexOf(table.CurrentSB)
 	if sbIndex == -1 {
-		sbIndex = 0
+		sbIndex = table.SBSeat - 1
 	}
 
 	newSBIndex := table.getNextActivePlayerIndex(sbIndex)
-	newBBIndex := table.getNextActivePlayerIndex(newSBIndex)
+	newBBIndex := table.getNextBBIndex(newSBIndex)
 
 	if newSBIndex != -1 {
 		if newBBIndex == newSBIndex {
-			newBBIndex = table.getNextActivePlayerIndex(newBBIndex)
+			newBBIndex = table.getNextBBIndex(newBBIndex)
 		}
-		table.CurrentSB = table.Players[newSBIndex].ID
-		table.CurrentBB = table.Players[newBBIndex].ID
+		table.CurrentSB = table.Seats[newSBIndex].Player.ID
+		table.CurrentBB = table.Seats[newBBIndex].Player.ID
+		table.SBSeat = table.Seats[newSBIndex].Number
 	}
 }
 
+// SetEliminatePlayersWithNoChips flags busted players as eliminated. On cash
+// tables they are sat out instead, keeping their seat until they rebuy or
+// leave.
 func (table *Table) SetEliminatePlayersWithNoChips() {
-	for i := range table.Players {
-		if table.Players[i].Chips <= 0 {
-			table.Players[i].IsEliminated = true
+	for _, player := range table.SeatedPlayers() {
+		if player.Chips > 0 {
+			continue
 		}
+		if table.GameType == GameCash {
+			player.SittingOut = true
+			player.WaitForBB = true
+			continue
+		}
+		player.IsEliminated = true
 	}
 }
 
+// RemovePlayersEliminatedWithNoChips empties the seats of eliminated players.
+// The remaining players keep their seat numbers.
 func (table *Table) RemovePlayersEliminatedWithNoChips() {
-	var remainingPlayers []Player
-	for _, player := range table.Players {
-		if !player.IsEliminated {
-			remainingPlayers = append(remainingPlayers, player)
+	for i := range table.Seats {
+		player := table.Seats[i].Player
+		if player != nil && player.IsEliminated {
+			table.Seats[i].Player = nil
 		}
 	}
-	table.Players = remainingPlayers
 }
 
 func (table *Table) getNextActivePlayerIndex(startIndex int) int {
-	for i := 1; i <= len(table.Players); i++ {
-		currentIndex := (startIndex + i) % len(table.Players)
-		if !table.Players[currentIndex].IsEliminated {
-			return currentIndex
+	return table.nextSeatIndex(startIndex, func(player *Player) bool {
+		return !player.IsEliminated && !player.SittingOut
+	})
+}
+
+// getNextBBIndex is like getNextActivePlayerIndex but also stops at players
+// sitting out to wait for the big blind, which deals them back in.
+func (table *Table) getNextBBIndex(startIndex int) int {
+	return table.nextSeatIndex(startIndex, func(player *Player) bool {
+		if player.IsEliminated {
+			return false
 		}
-	}
-	return -1
+		return !player.SittingOut || (player.WaitForBB && player.Chips > 0)
+	})
 }
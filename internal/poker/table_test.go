@@ -1,43 +1,60 @@
 package poker
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func newTestTable(t *testing.T, players ...Player) *Table {
+	table, err := NewTable("test", MaxTableSize)
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	for i, player := range players {
+		if err := table.SitPlayer(i+1, player); err != nil {
+			t.Fatalf("Failed to seat player: %v", err)
+		}
+	}
+	return &table
+}
+
 func TestDealCards(t *testing.T) {
-	table := &Table{}
+	table := newTestTable(t,
+		Player{ID: "player1"}, Player{ID: "player2"}, Player{ID: "player3"}, Player{ID: "player4"},
+		Player{ID: "player5"}, Player{ID: "player6"}, Player{ID: "player7"},
+	)
 	table.DealCards()
 
-	assert.Equal(t, 7, len(table.Players), "Should have cards for all players")
+	for _, player := range table.SeatedPlayers() {
+		assert.Len(t, player.Cards, 2, "Should have cards for all players")
+	}
 	assert.Len(t, table.FlopCards, 3, "There should be 3 cards in the flop")
 	assert.NotNil(t, table.TurnCard, "There should be 1 card on the turn")
 	assert.NotNil(t, table.RiverCard, "There should be 1 card on the river")
 }
 
 func TestCompareHands(t *testing.T) {
-	table := &Table{
-		FlopCards: []Card{
-			{Suit: "Clubs", Value: "6"},
-			{Suit: "Hearts", Value: "6"},
-			{Suit: "Diamonds", Value: "6"},
-		},
-		TurnCard:  &Card{Suit: "Spades", Value: "7"},
-		RiverCard: &Card{Suit: "Hearts", Value: "2"},
-		Players: []Player{
-			// Player 1 with a four-of-a-kind (poker)
-			{ID: "player1", Cards: []Card{{Suit: "Clubs", Value: "6"}, {Suit: "Spades", Value: "2"}}},
-			// Player 2 with a full house
-			{ID: "player2", Cards: []Card{{Suit: "Hearts", Value: "K"}, {Suit: "Diamonds", Value: "K"}}},
-			// Player 3 with a flush
-			{ID: "player3", Cards: []Card{{Suit: "Hearts", Value: "Q"}, {Suit: "Hearts", Value: "J"}}, HasFold: true},
-			// Player 4 with a straight
-			{ID: "player4", Cards: []Card{{Suit: "Spades", Value: "8"}, {Suit: "Clubs", Value: "7"}}, HasFold: true},
-			// Player 5 with a pair
-			{ID: "player5", Cards: []Card{{Suit: "Diamonds", Value: "3"}, {Suit: "Clubs", Value: "4"}}},
-		},
+	table := newTestTable(t,
+		// Player 1 with a four-of-a-kind (poker)
+		Player{ID: "player1", Cards: []Card{{Suit: "Clubs", Value: "6"}, {Suit: "Spades", Value: "2"}}},
+		// Player 2 with a full house
+		Player{ID: "player2", Cards: []Card{{Suit: "Hearts", Value: "K"}, {Suit: "Diamonds", Value: "K"}}},
+		// Player 3 with a flush
+		Player{ID: "player3", Cards: []Card{{Suit: "Hearts", Value: "Q"}, {Suit: "Hearts", Value: "J"}}, HasFold: true},
+		// Player 4 with a straight
+		Player{ID: "player4", Cards: []Card{{Suit: "Spades", Value: "8"}, {Suit: "Clubs", Value: "7"}}, HasFold: true},
+		// Player 5 with a pair
+		Player{ID: "player5", Cards: []Card{{Suit: "Diamonds", Value: "3"}, {Suit: "Clubs", Value: "4"}}},
+	)
+	table.FlopCards = []Card{
+		{Suit: "Clubs", Value: "6"},
+		{Suit: "Hearts", Value: "6"},
+		{Suit: "Diamonds", Value: "6"},
 	}
+	table.TurnCard = &Card{Suit: "Spades", Value: "7"}
+	table.RiverCard = &Card{Suit: "Hearts", Value: "2"}
 
 	// Evaluar las manos de todos los jugadores
 	table.EvaluateHand()
@@ -60,3 +77,89 @@ func TestConvertCardToEvalCard(t *testing.T) {
 	// Imprime para verificar el resultado
 	t.Logf("Converted eval card: %v", evalCard)
 }
+
+func TestVoidHand(t *testing.T) {
+	table := newTestTable(t,
+		Player{ID: "player1", Chips: 1000},
+		Player{ID: "player2", Chips: 1000},
+		Player{ID: "player3", Chips: 1000},
+	)
+	table.BBValue = 100
+	table.CurrentSB = "player1"
+	table.CurrentBB = "player2"
+	table.SetSMBB()
+	player3 := table.FindPlayer("player3")
+	player3.Chips -= 300
+	player3.TotalBet += 300
+	table.TotalBet += 300
+
+	table.VoidHand("invalid deal")
+
+	for _, player := range table.SeatedPlayers() {
+		assert.Equal(t, 1000, player.Chips, "Stack should be restored for %s", player.ID)
+		assert.Equal(t, 0, player.TotalBet)
+	}
+	assert.Equal(t, 0, table.TotalBet)
+	assert.Equal(t, "invalid deal", table.VoidReason)
+
+	table.SMBBTurn()
+	assert.Equal(t, "player1", table.CurrentSB, "Blinds should not move after a voided hand")
+	assert.Equal(t, "player2", table.CurrentBB)
+}
+
+func TestSeatsAreStableAfterElimination(t *testing.T) {
+	table := newTestTable(t,
+		Player{ID: "player1", Chips: 1000},
+		Player{ID: "player2", Chips: 0},
+		Player{ID: "player3", Chips: 1000},
+	)
+	table.CurrentSB = "player1"
+	table.CurrentBB = "player2"
+
+	table.SetEliminatePlayersWithNoChips()
+	table.SMBBTurn()
+	table.RemovePlayersEliminatedWithNoChips()
+
+	assert.Equal(t, 2, table.PlayerCount())
+	assert.True(t, table.Seats[1].IsEmpty(), "Seat 2 should be empty")
+	assert.Equal(t, 3, table.FindPlayer("player3").Seat, "Player 3 should keep seat 3")
+	assert.Equal(t, "player3", table.CurrentSB)
+	assert.Equal(t, "player1", table.CurrentBB)
+
+	err := table.SitPlayer(3, Player{ID: "player4"})
+	assert.Error(t, err, "Should not seat a player in a taken seat")
+	seat, err := table.SitPlayerAnywhere(Player{ID: "player4"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, seat)
+}
+
+func TestUnmarshalTableSeatsLegacyPlayers(t *testing.T) {
+	var legacy Table
+	err := json.Unmarshal([]byte(`{"ID":"1","CurrentBB":"b","Players":[{"ID":"a","Chips":990,"TotalBet":10},{"ID":"b","Chips":980,"TotalBet":20}]}`), &legacy)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", legacy.CurrentBB)
+	assert.Equal(t, 2, legacy.PlayerCount())
+	assert.Equal(t, 2, legacy.FindPlayer("b").Seat)
+	assert.Equal(t, 20, legacy.FindPlayer("b").TotalBet)
+
+	table := newTestTable(t, Player{ID: "a", Chips: 1000})
+	data, err := json.Marshal(table)
+	assert.NoError(t, err)
+	var decoded Table
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, *table, decoded)
+}
+
+func TestDealFromIsRepeatable(t *testing.T) {
+	deck := ShuffledDeck()
+	first := newTestTable(t, Player{ID: "player1"}, Player{ID: "player2"})
+	second := newTestTable(t, Player{ID: "player1"}, Player{ID: "player2"})
+
+	first.DealFrom(deck)
+	second.DealFrom(deck)
+
+	assert.Equal(t, first.FindPlayer("player1").Cards, second.FindPlayer("player1").Cards)
+	assert.Equal(t, first.FlopCards, second.FlopCards)
+	assert.Equal(t, first.RiverCard, second.RiverCard)
+	assert.Len(t, deck, 52, "Dealing should not consume the deck")
+}
@@ -1,5 +1,10 @@
 package poker
 
+import (
+	"fmt"
+	"sort"
+)
+
 type Tournament struct {
 	ID                    string
 	Name                  string
@@ -8,9 +13,79 @@ type Tournament struct {
 	StartDate             string
 	EndDate               string
 	Prize                 string
-	Configuration         string
+	Configuration         TournamentConfig
 	Ongoing               bool
 	MinPlayers            int
 	MaxPlayers            int
 	TurnSeconds           int
+	TableSize             int
+}
+
+func TournamentTableID(tournamentID string, number int) string {
+	return fmt.Sprintf("%s-%d", tournamentID, number)
+}
+
+// SeatTournament spreads players over as few tables of tableSize seats as
+// possible, keeping table sizes within one player of each other. Players are
+// seated in the order given, so callers shuffle them first for a random draw.
+func SeatTournament(tournamentID string, players []Player, tableSize int, startingChips int) ([]Table, error) {
+	if len(players) < 2 {
+		return nil, fmt.Errorf("tournament %s needs at least 2 players, got %d", tournamentID, len(players))
+	}
+	if startingChips <= 0 {
+		return nil, fmt.Errorf("tournament %s has no starting chips", tournamentID)
+	}
+
+	tableCount := (len(players) + tableSize - 1) / tableSize
+	tables := make([]Table, tableCount)
+	for i := range tables {
+		table, err := NewTable(TournamentTableID(tournamentID, i+1), tableSize)
+		if err != nil {
+			return nil, err
+		}
+		table.GameType = GameTournament
+		table.TournamentID = tournamentID
+		table.Number = i + 1
+		tables[i] = table
+	}
+
+	for i, player := range players {
+		player.Chips = startingChips
+		if _, err := tables[i%tableCount].SitPlayerAnywhere(player); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// RecordStartingStacks remembers every stack before the blinds and antes of a
+// hand are posted, to rank players who bust in the same hand.
+func (table *Table) RecordStartingStacks() {
+	for _, player := range table.SeatedPlayers() {
+		player.HandStartChips = player.Chips
+	}
+}
+
+// SortEliminations orders players who busted in the same hand, or the same
+// hand-for-hand round, from the smallest starting stack to the largest, the
+// order in which they finish.
+func SortEliminations(players []Player) {
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i].HandStartChips < players[j].HandStartChips
+	})
+}
+
+// StandUpEliminated removes the players eliminated in the last hand and
+// returns them.
+func (table *Table) StandUpEliminated() []Player {
+	var eliminated []Player
+	for _, player := range table.SeatedPlayers() {
+		if player.IsEliminated {
+			eliminated = append(eliminated, *player)
+		}
+	}
+	for _, player := range eliminated {
+		table.StandUp(player.ID)
+	}
+	return eliminated
 }
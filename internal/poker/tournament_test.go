@@ -0,0 +1,64 @@
+package poker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeatTournament(t *testing.T) {
+	var players []Player
+	for i := 1; i <= 20; i++ {
+		players = append(players, Player{ID: fmt.Sprintf("p%d", i)})
+	}
+
+	tables, err := SeatTournament("7", players, 9, 1500)
+	assert.NoError(t, err)
+	assert.Len(t, tables, 3)
+	for _, table := range tables {
+		assert.Equal(t, "7", table.TournamentID)
+		assert.Equal(t, GameTournament, table.GameType)
+		assert.GreaterOrEqual(t, table.PlayerCount(), 6)
+		assert.LessOrEqual(t, table.PlayerCount(), 7)
+	}
+	assert.Equal(t, "7-1", tables[0].ID)
+	assert.Equal(t, 1500, tables[2].FindPlayer("p3").Chips)
+
+	_, err = SeatTournament("7", players[:1], 9, 1500)
+	assert.Error(t, err)
+}
+
+func TestStandUpEliminated(t *testing.T) {
+	table := newTestTable(t,
+		Player{ID: "a", Chips: 0},
+		Player{ID: "b", Chips: 500},
+		Player{ID: "c", Chips: 0},
+	)
+	table.GameType = GameTournament
+	table.SetEliminatePlayersWithNoChips()
+
+	eliminated := table.StandUpEliminated()
+	assert.Len(t, eliminated, 2)
+	assert.Equal(t, 1, table.PlayerCount())
+	assert.NotNil(t, table.FindPlayer("b"))
+}
+
+func TestSortEliminationsByStartingStack(t *testing.T) {
+	table := newTestTable(t,
+		Player{ID: "a", Chips: 800},
+		Player{ID: "b", Chips: 300},
+		Player{ID: "c", Chips: 500},
+	)
+	table.RecordStartingStacks()
+
+	var busted []Player
+	for _, player := range table.SeatedPlayers() {
+		busted = append(busted, *player)
+	}
+	SortEliminations(busted)
+
+	assert.Equal(t, "b", busted[0].ID, "The shortest stack finishes lowest")
+	assert.Equal(t, "c", busted[1].ID)
+	assert.Equal(t, "a", busted[2].ID)
+}
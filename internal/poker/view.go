@@ -0,0 +1,77 @@
+package poker
+
+import "fmt"
+
+// HandAction is one decision in the action history of a hand. Amount is what
+// the player put into the pot with it.
+type HandAction struct {
+	Stage    string
+	PlayerID string
+	Action   string
+	Amount   int
+	Timeout  bool `json:",omitempty"`
+}
+
+// Clone returns a copy of the table that shares no players with it.
+func (table *Table) Clone() Table {
+	clone := *table
+	clone.Seats = make([]Seat, len(table.Seats))
+	for i, seat := range table.Seats {
+		clone.Seats[i] = seat
+		if seat.Player != nil {
+			player := *seat.Player
+			clone.Seats[i].Player = &player
+		}
+	}
+	clone.Pots = append([]Pot(nil), table.Pots...)
+	clone.History = append([]HandAction(nil), table.History...)
+	return clone
+}
+
+// PublicView returns the table as every player sees it: hole cards are only
+// shown for the players still in the hand at showdown.
+func (table *Table) PublicView() Table {
+	view := table.Clone()
+	for _, player := range view.SeatedPlayers() {
+		if view.CurrentStage != "ShowDown" || player.HasFold {
+			player.Cards = nil
+		}
+	}
+	return view
+}
+
+// PrivateView returns the public view with the hole cards of playerID, taken
+// from dealt, the table the hand was dealt on.
+func (table *Table) PrivateView(playerID string, dealt *Table) (Table, error) {
+	if table.FindPlayer(playerID) == nil {
+		return Table{}, fmt.Errorf("player %s is not seated at table %s", playerID, table.ID)
+	}
+
+	view := table.PublicView()
+	if dealt != nil {
+		if cards := dealt.FindPlayer(playerID); cards != nil {
+			view.FindPlayer(playerID).Cards = cards.Cards
+		}
+	}
+	return view, nil
+}
+
+// PotBreakdown returns the pots of the hand. Before they are settled they are
+// built from the chips committed so far.
+func (table *Table) PotBreakdown() []Pot {
+	if table.Pots != nil {
+		return table.Pots
+	}
+	view := table.Clone()
+	return view.BuildPots()
+}
+
+func (table *Table) recordAction(player *Player, amount int, timeout bool) {
+	table.History = append(table.History, HandAction{
+		Stage:    table.CurrentStage,
+		PlayerID: player.ID,
+		Action:   player.LastAction,
+		Amount:   amount,
+		Timeout:  timeout,
+	})
+}
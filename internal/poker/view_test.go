@@ -0,0 +1,45 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicViewHidesHoleCards(t *testing.T) {
+	table := newTestTable(t, Player{ID: "player1", Chips: 100}, Player{ID: "player2", Chips: 100})
+	table.DealCards()
+
+	view := table.PublicView()
+	for _, player := range view.SeatedPlayers() {
+		assert.Empty(t, player.Cards, "Hole cards should be hidden before showdown")
+	}
+	assert.Len(t, table.FindPlayer("player1").Cards, 2, "The view should not change the table")
+
+	private, err := view.PrivateView("player1", table)
+	assert.NoError(t, err)
+	assert.Equal(t, table.FindPlayer("player1").Cards, private.FindPlayer("player1").Cards)
+	assert.Empty(t, private.FindPlayer("player2").Cards, "Other players' cards should stay hidden")
+
+	_, err = view.PrivateView("stranger", table)
+	assert.Error(t, err)
+}
+
+func TestHistoryAndPotBreakdown(t *testing.T) {
+	table := newFlopTable(t)
+	_, err := table.StartBettingRound()
+	assert.NoError(t, err)
+	_, err = table.ApplyAction(PlayerAction{PlayerID: "player3", Action: "raise", Amount: 100})
+	assert.NoError(t, err)
+	table.TimeoutTurn()
+
+	assert.Equal(t, []HandAction{
+		{Stage: "flop", PlayerID: "player3", Action: "raise", Amount: 100},
+		{Stage: "flop", PlayerID: "player1", Action: "fold", Timeout: true},
+	}, table.History)
+
+	pots := table.PotBreakdown()
+	assert.Len(t, pots, 1)
+	assert.Equal(t, 100, pots[0].Amount)
+	assert.Equal(t, 900, table.FindPlayer("player3").Chips, "Building the breakdown should not move chips")
+}
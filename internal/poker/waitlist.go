@@ -0,0 +1,120 @@
+package poker
+
+import (
+	"fmt"
+	"time"
+)
+
+// WaitingList queues players for a seat at one table or at any table of a
+// stake. A seat that opens is offered to the first player in line, who holds
+// it until the offer expires.
+type WaitingList struct {
+	Key     string
+	Entries []WaitingEntry
+	Offers  []SeatOffer
+}
+
+type WaitingEntry struct {
+	PlayerID string
+	WalletID uint
+	JoinedAt time.Time
+}
+
+type SeatOffer struct {
+	PlayerID  string
+	WalletID  uint
+	TableID   string
+	Seat      int
+	StakeKey  string
+	ExpiresAt time.Time
+}
+
+func TableWaitingListKey(tableID string) string {
+	return "table-" + tableID
+}
+
+func StakeWaitingListKey(smallBlind int, bigBlind int) string {
+	return fmt.Sprintf("stake-%d-%d", smallBlind, bigBlind)
+}
+
+func WaitingListSubject(key string, playerID string) string {
+	return fmt.Sprintf("pokerServer.waitingList.%s.%s", key, playerID)
+}
+
+func (list *WaitingList) Join(entry WaitingEntry) error {
+	if list.Position(entry.PlayerID) != 0 {
+		return fmt.Errorf("player %s is already on waiting list %s", entry.PlayerID, list.Key)
+	}
+	if _, ok := list.FindOffer(entry.PlayerID); ok {
+		return fmt.Errorf("player %s already has a seat offer from waiting list %s", entry.PlayerID, list.Key)
+	}
+	list.Entries = append(list.Entries, entry)
+	return nil
+}
+
+// Leave removes the player from the line or withdraws their pending offer.
+func (list *WaitingList) Leave(playerID string) bool {
+	for i, entry := range list.Entries {
+		if entry.PlayerID == playerID {
+			list.Entries = append(list.Entries[:i], list.Entries[i+1:]...)
+			return true
+		}
+	}
+	_, ok := list.TakeOffer(playerID)
+	return ok
+}
+
+// Position returns the 1-based place of the player in line, or 0.
+func (list *WaitingList) Position(playerID string) int {
+	for i, entry := range list.Entries {
+		if entry.PlayerID == playerID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Offer gives the seat to the first player in line. It returns false when
+// nobody is waiting.
+func (list *WaitingList) Offer(tableID string, seat int, stakeKey string, expiresAt time.Time) (SeatOffer, bool) {
+	if len(list.Entries) == 0 {
+		return SeatOffer{}, false
+	}
+	entry := list.Entries[0]
+	list.Entries = list.Entries[1:]
+
+	offer := SeatOffer{
+		PlayerID:  entry.PlayerID,
+		WalletID:  entry.WalletID,
+		TableID:   tableID,
+		Seat:      seat,
+		StakeKey:  stakeKey,
+		ExpiresAt: expiresAt,
+	}
+	list.Offers = append(list.Offers, offer)
+	return offer, true
+}
+
+func (list *WaitingList) FindOffer(playerID string) (SeatOffer, bool) {
+	for _, offer := range list.Offers {
+		if offer.PlayerID == playerID {
+			return offer, true
+		}
+	}
+	return SeatOffer{}, false
+}
+
+// TakeOffer removes and returns the pending offer made to the player.
+func (list *WaitingList) TakeOffer(playerID string) (SeatOffer, bool) {
+	for i, offer := range list.Offers {
+		if offer.PlayerID == playerID {
+			list.Offers = append(list.Offers[:i], list.Offers[i+1:]...)
+			return offer, true
+		}
+	}
+	return SeatOffer{}, false
+}
+
+func (list *WaitingList) IsIdle() bool {
+	return len(list.Entries) == 0 && len(list.Offers) == 0
+}
@@ -0,0 +1,46 @@
+package poker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitingListOffersInOrder(t *testing.T) {
+	list := WaitingList{Key: TableWaitingListKey("1")}
+	assert.NoError(t, list.Join(WaitingEntry{PlayerID: "player1"}))
+	assert.NoError(t, list.Join(WaitingEntry{PlayerID: "player2"}))
+	assert.Error(t, list.Join(WaitingEntry{PlayerID: "player1"}), "Should not join twice")
+	assert.Equal(t, 2, list.Position("player2"))
+
+	expires := time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC)
+	offer, ok := list.Offer("1", 4, StakeWaitingListKey(1, 2), expires)
+	assert.True(t, ok)
+	assert.Equal(t, "player1", offer.PlayerID)
+	assert.Equal(t, 4, offer.Seat)
+	assert.Equal(t, 1, list.Position("player2"), "Next player should move up")
+
+	_, ok = list.TakeOffer("player1")
+	assert.True(t, ok)
+	offer, ok = list.Offer("1", 4, "", expires)
+	assert.True(t, ok)
+	assert.Equal(t, "player2", offer.PlayerID, "Seat should go to the next in line")
+
+	assert.True(t, list.Leave("player2"))
+	assert.True(t, list.IsIdle())
+	_, ok = list.Offer("1", 4, "", expires)
+	assert.False(t, ok)
+}
+
+func TestReservedSeat(t *testing.T) {
+	table := newTestCashTable(t)
+	assert.NoError(t, table.ReserveSeat(1, "player1"))
+
+	assert.Error(t, table.CheckJoin(1, "player2", 100), "Reserved seat should be held")
+	assert.NoError(t, table.CheckJoin(1, "player1", 100))
+
+	seat, err := table.JoinCash(0, Player{ID: "player2"}, 100, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, seat, "Players joining anywhere should skip reserved seats")
+}
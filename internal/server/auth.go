@@ -0,0 +1,23 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"server/internal/auth"
+)
+
+// requireStaff lets only callers with a staff token through to next.
+func requireStaff(v *auth.Verifier, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := v.FromRequest(r)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+		if !id.Staff() {
+			writeError(w, http.StatusForbidden, errors.New("staff only"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
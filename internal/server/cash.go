@@ -0,0 +1,52 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"server/config"
+	"server/internal/poker"
+	temporal "server/internal/workflow"
+
+	"github.com/gorilla/mux"
+	"go.temporal.io/sdk/client"
+)
+
+type createCashTableRequest struct {
+	ID     string
+	Seats  int
+	Config poker.CashConfig
+}
+
+func registerCashTableRoutes(r *mux.Router, cfg *config.Config, c client.Client) {
+	r.HandleFunc("/cash-tables", createCashTable(cfg, c)).Methods(http.MethodPost)
+	r.HandleFunc("/cash-tables/{id}/join", signalTable(c, temporal.JoinTableSignal, func() interface{} { return &temporal.JoinTableRequest{} })).Methods(http.MethodPost)
+	r.HandleFunc("/cash-tables/{id}/top-up", signalTable(c, temporal.TopUpSignal, func() interface{} { return &temporal.TopUpRequest{} })).Methods(http.MethodPost)
+	r.HandleFunc("/cash-tables/{id}/leave", signalTable(c, temporal.LeaveTableSignal, func() interface{} { return &temporal.LeaveTableRequest{} })).Methods(http.MethodPost)
+}
+
+func createCashTable(cfg *config.Config, c client.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req createCashTableRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		table, err := poker.NewCashTable(req.ID, req.Seats, req.Config)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		_, err = c.ExecuteWorkflow(r.Context(), client.StartWorkflowOptions{
+			ID:        temporal.TableWorkflowID(table.ID),
+			TaskQueue: temporal.TableTaskQueue(cfg, table.ID),
+		}, temporal.CashTableWorkflow, table, cfg)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, table)
+	}
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+	"server/config"
+	"server/internal/auth"
+	"server/internal/codec"
+
+	"github.com/gorilla/mux"
+)
+
+// registerCodecRoutes serves the codec server for the Temporal UI under
+// /codec/ to staff only. The UI must pass the staff member's access token.
+// Nothing is served when payloads are not encrypted.
+func registerCodecRoutes(r *mux.Router, cfg *config.Config, v *auth.Verifier) error {
+	if len(cfg.Codec.Keys) == 0 {
+		return nil
+	}
+	c, err := codec.New(cfg.Codec)
+	if err != nil {
+		return err
+	}
+	handler := codec.CORS(cfg.Codec.AllowedOrigins, requireStaff(v, codec.NewHTTPHandler(c)))
+	r.PathPrefix("/codec/").Methods(http.MethodPost, http.MethodOptions).Handler(handler)
+	return nil
+}
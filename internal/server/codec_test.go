@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/config"
+	"server/internal/auth"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodecRequiresStaff(t *testing.T) {
+	cfg := &config.Config{Codec: config.CodecConfig{
+		ActiveKey: "k1",
+		Keys:      []config.CodecKey{{ID: "k1", Key: "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="}},
+	}}
+	r := mux.NewRouter()
+	require.NoError(t, registerCodecRoutes(r, cfg, auth.NewVerifier(testSecret)))
+
+	for _, tc := range []struct {
+		name          string
+		path          string
+		authorization string
+		status        int
+	}{
+		{"anonymous", "/codec/decode", "", http.StatusUnauthorized},
+		{"token in path", "/codec/" + testSecret + "/decode", "", http.StatusUnauthorized},
+		{"player", "/codec/decode", bearer(t, auth.Identity{PlayerID: "player1"}), http.StatusForbidden},
+		{"staff", "/codec/decode", bearer(t, auth.Identity{PlayerID: "support", Role: auth.RoleStaff}), http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(`{"payloads":[]}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
@@ -1,19 +1,65 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
 	"log"
 	"net/http"
 	"server/config"
+	"server/internal/auth"
+	"time"
 
 	"github.com/gorilla/mux"
+	"go.temporal.io/sdk/client"
 )
 
-func StartServer(cfg *config.Config) {
+// shutdownTimeout is how long requests in flight get to finish on shutdown.
+const shutdownTimeout = 15 * time.Second
+
+// Run serves the API until ctx is done, then stops taking requests and lets
+// the ones in flight finish.
+func Run(ctx context.Context, cfg *config.Config, c client.Client) error {
+	verifier := auth.NewVerifier(cfg.Auth.Secret)
+	if !verifier.Enabled() {
+		log.Println("No auth secret configured, every API caller is anonymous")
+	}
+
 	r := mux.NewRouter()
+	registerCashTableRoutes(r, cfg, c)
+	registerWaitingListRoutes(r, cfg, c)
+	registerTournamentRoutes(r, cfg, c, verifier)
+	registerTableRoutes(r, cfg, c, verifier)
+	if err := registerCodecRoutes(r, cfg, verifier); err != nil {
+		return err
+	}
+
+	srv := &http.Server{Addr: ":" + cfg.Server.Port, Handler: r}
+	errs := make(chan error, 1)
+	go func() {
+		log.Printf("Server is listening on port%s", srv.Addr)
+		errs <- srv.ListenAndServe()
+	}()
 
-	port := ":" + cfg.Server.Port
-	log.Printf("Server is listening on port%s", port)
-	if err := http.ListenAndServe(port, r); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
 	}
+
+	log.Println("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
 }
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"server/config"
+	"server/internal/auth"
+	temporal "server/internal/workflow"
+
+	"github.com/gorilla/mux"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+// registerTableRoutes exposes the live state of any running table, cash or
+// tournament, for support staff and reconnecting clients, and the controls
+// tournament directors use on it.
+func registerTableRoutes(r *mux.Router, cfg *config.Config, c client.Client, v *auth.Verifier) {
+	r.HandleFunc("/tables/{id}", queryTable(c, temporal.TableViewQuery)).Methods(http.MethodGet)
+	r.HandleFunc("/tables/{id}/players/{playerID}", playerView(c, v)).Methods(http.MethodGet)
+	r.HandleFunc("/tables/{id}/stage", queryTable(c, temporal.StageQuery)).Methods(http.MethodGet)
+	r.HandleFunc("/tables/{id}/pots", queryTable(c, temporal.PotsQuery)).Methods(http.MethodGet)
+	r.HandleFunc("/tables/{id}/history", queryTable(c, temporal.HistoryQuery)).Methods(http.MethodGet)
+	r.Handle("/tables/{id}/control", requireStaff(v, controlTable(cfg, c))).Methods(http.MethodPost)
+	r.Handle("/tables/{id}/void", requireStaff(v, signalTable(c, temporal.VoidHandSignal, func() interface{} { return &temporal.VoidHandRequest{} }))).Methods(http.MethodPost)
+}
+
+// playerView answers the view of a seat, hole cards included, to the player
+// sitting there and to staff. Anonymous callers and other players get the
+// public view.
+func playerView(c client.Client, v *auth.Verifier) http.HandlerFunc {
+	private := queryTable(c, temporal.PlayerViewQuery, "playerID")
+	public := queryTable(c, temporal.TableViewQuery)
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := v.FromRequest(r)
+		if errors.Is(err, auth.ErrNoToken) {
+			public(w, r)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+		if id.Staff() || id.PlayerID == mux.Vars(r)["playerID"] {
+			private(w, r)
+			return
+		}
+		public(w, r)
+	}
+}
+
+// controlTable forwards a control request to the table workflow. A table can
+// only be moved to a shard the configuration knows about.
+func controlTable(cfg *config.Config, c client.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req temporal.ControlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if req.Action == temporal.ControlMove && !temporal.IsShard(cfg, req.Shard) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unknown shard %q", req.Shard))
+			return
+		}
+
+		tableID := mux.Vars(r)["id"]
+		if err := c.SignalWorkflow(r.Context(), temporal.TableWorkflowID(tableID), "", temporal.ControlSignal, req); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		writeJSON(w, http.StatusAccepted, req)
+	}
+}
+
+// queryTable proxies queryType to the table workflow, passing the URL
+// variables named in args as query arguments.
+func queryTable(c client.Client, queryType string, args ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		queryArgs := make([]interface{}, len(args))
+		for i, name := range args {
+			queryArgs[i] = vars[name]
+		}
+
+		value, err := c.QueryWorkflow(r.Context(), temporal.TableWorkflowID(vars["id"]), "", queryType, queryArgs...)
+		if err != nil {
+			var notFound *serviceerror.NotFound
+			if errors.As(err, &notFound) {
+				writeError(w, http.StatusNotFound, err)
+				return
+			}
+			var queryFailed *serviceerror.QueryFailed
+			if errors.As(err, &queryFailed) {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		var result interface{}
+		if err := value.Get(&result); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// signalTable decodes the request body into the value returned by
+// newRequest and forwards it to the table workflow as signalName.
+func signalTable(c client.Client, signalName string, newRequest func() interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := newRequest()
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		tableID := mux.Vars(r)["id"]
+		if err := c.SignalWorkflow(r.Context(), temporal.TableWorkflowID(tableID), "", signalName, req); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		writeJSON(w, http.StatusAccepted, req)
+	}
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/config"
+	"server/internal/auth"
+	temporal "server/internal/workflow"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/mocks"
+)
+
+const testSecret = "test-secret"
+
+func newTableRouter(t *testing.T) (*mux.Router, *mocks.Client) {
+	c := mocks.NewClient(t)
+	r := mux.NewRouter()
+	registerTableRoutes(r, &config.Config{}, c, auth.NewVerifier(testSecret))
+	return r, c
+}
+
+func bearer(t *testing.T, id auth.Identity) string {
+	token, err := auth.NewVerifier(testSecret).Sign(id, time.Hour)
+	require.NoError(t, err)
+	return "Bearer " + token
+}
+
+func expectQuery(c *mocks.Client, queryType string, args ...interface{}) {
+	value := &mocks.Value{}
+	value.On("Get", mock.Anything).Return(nil)
+	callArgs := append([]interface{}{mock.Anything, temporal.TableWorkflowID("t1"), "", queryType}, args...)
+	c.On("QueryWorkflow", callArgs...).Return(value, nil).Once()
+}
+
+func TestPlayerViewHidesCardsFromOthers(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		authorization string
+	}{
+		{"anonymous", ""},
+		{"other player", bearer(t, auth.Identity{PlayerID: "player2"})},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, c := newTableRouter(t)
+			expectQuery(c, temporal.TableViewQuery)
+
+			req := httptest.NewRequest(http.MethodGet, "/tables/t1/players/player1", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			c.AssertNotCalled(t, "QueryWorkflow", mock.Anything, mock.Anything, mock.Anything, temporal.PlayerViewQuery, mock.Anything)
+		})
+	}
+}
+
+func TestPlayerViewShowsCardsToPlayerAndStaff(t *testing.T) {
+	for _, id := range []auth.Identity{{PlayerID: "player1"}, {PlayerID: "support", Role: auth.RoleStaff}} {
+		r, c := newTableRouter(t)
+		expectQuery(c, temporal.PlayerViewQuery, "player1")
+
+		req := httptest.NewRequest(http.MethodGet, "/tables/t1/players/player1", nil)
+		req.Header.Set("Authorization", bearer(t, id))
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+}
+
+func TestPlayerViewRejectsInvalidToken(t *testing.T) {
+	r, _ := newTableRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/tables/t1/players/player1", nil)
+	req.Header.Set("Authorization", "Bearer forged")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestControlRequiresStaff(t *testing.T) {
+	c := mocks.NewClient(t)
+	r := mux.NewRouter()
+	v := auth.NewVerifier(testSecret)
+	registerTableRoutes(r, &config.Config{}, c, v)
+	registerTournamentRoutes(r, &config.Config{}, c, v)
+
+	for _, path := range []string{"/tables/t1/control", "/tables/t1/void", "/tournaments/1/control"} {
+		for _, tc := range []struct {
+			authorization string
+			status        int
+		}{
+			{"", http.StatusUnauthorized},
+			{bearer(t, auth.Identity{PlayerID: "player1"}), http.StatusForbidden},
+		} {
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"Action":"terminate"}`))
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code, path)
+		}
+	}
+	c.AssertNotCalled(t, "SignalWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	c.On("SignalWorkflow", mock.Anything, temporal.TableWorkflowID("t1"), "", temporal.ControlSignal, mock.Anything).Return(nil).Once()
+	req := httptest.NewRequest(http.MethodPost, "/tables/t1/control", strings.NewReader(`{"Action":"pause"}`))
+	req.Header.Set("Authorization", bearer(t, auth.Identity{PlayerID: "director", Role: auth.RoleStaff}))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+}
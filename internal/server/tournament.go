@@ -0,0 +1,292 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"server/config"
+	"server/internal/auth"
+	"server/internal/db"
+	"server/internal/db/models"
+	"server/internal/poker"
+	temporal "server/internal/workflow"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"gorm.io/gorm"
+)
+
+func registerTournamentRoutes(r *mux.Router, cfg *config.Config, c client.Client, v *auth.Verifier) {
+	r.HandleFunc("/tournaments", createTournament(cfg, c)).Methods(http.MethodPost)
+	r.HandleFunc("/tournaments/{id}", updateTournament(cfg, c)).Methods(http.MethodPut)
+	r.Handle("/tournaments/{id}", requireStaff(v, cancelTournament(c))).Methods(http.MethodDelete)
+	r.HandleFunc("/tournaments/{id}/payouts", getTournamentPayouts).Methods(http.MethodGet)
+	r.HandleFunc("/tournaments/{id}/open", openSitAndGo(cfg, c)).Methods(http.MethodPost)
+	r.HandleFunc("/tournaments/{id}/register", signalTournament(c, temporal.TournamentRegisterSignal, newEntryRequest)).Methods(http.MethodPost)
+	r.HandleFunc("/tournaments/{id}/rebuy", signalTournament(c, temporal.RebuySignal, newEntryRequest)).Methods(http.MethodPost)
+	r.HandleFunc("/tournaments/{id}/add-on", signalTournament(c, temporal.AddOnSignal, newEntryRequest)).Methods(http.MethodPost)
+	r.HandleFunc("/tournaments/{id}/deal", signalTournament(c, temporal.ProposeDealSignal, func() interface{} { return &temporal.ProposeDealRequest{} })).Methods(http.MethodPost)
+	r.Handle("/tournaments/{id}/control", requireStaff(v, signalTournament(c, temporal.ControlSignal, newControlRequest))).Methods(http.MethodPost)
+}
+
+func newEntryRequest() interface{} { return &temporal.TournamentEntryRequest{} }
+
+func newControlRequest() interface{} { return &temporal.ControlRequest{} }
+
+// createTournament saves a tournament and schedules the opening and closing
+// of its registration and its start. Sit-and-gos are opened by hand instead.
+func createTournament(cfg *config.Config, c client.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var tournament models.Tournament
+		if err := json.NewDecoder(r.Body).Decode(&tournament); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		tournament.ID = 0
+		tournament.Ongoing = false
+		tournament.Cancelled = false
+		if err := validateTournament(tournament); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		err := db.GetDB().Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&tournament).Error; err != nil {
+				return err
+			}
+			if tournament.Configuration.SitAndGo {
+				return nil
+			}
+			return temporal.ScheduleTournament(r.Context(), c, tournament, cfg)
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, tournament)
+	}
+}
+
+// updateTournament edits a tournament that has not started yet and moves the
+// schedules that have not run.
+func updateTournament(cfg *config.Config, c client.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tournament, ok := loadTournament(w, r)
+		if !ok {
+			return
+		}
+		if tournament.Ongoing || tournament.Cancelled {
+			writeError(w, http.StatusConflict, fmt.Errorf("tournament %d has already started or was cancelled", tournament.ID))
+			return
+		}
+
+		id := tournament.ID
+		if err := json.NewDecoder(r.Body).Decode(&tournament); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		tournament.ID = id
+		tournament.Ongoing = false
+		tournament.Cancelled = false
+		if err := validateTournament(tournament); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		err := db.GetDB().Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(&tournament).Error; err != nil {
+				return err
+			}
+			if tournament.Configuration.SitAndGo {
+				return temporal.UnscheduleTournament(r.Context(), c, tournament.ID)
+			}
+			return temporal.RescheduleTournament(r.Context(), c, tournament, cfg)
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, tournament)
+	}
+}
+
+// cancelTournament removes the schedules of a tournament that has not
+// started. If its registration is open, the registration workflow refunds
+// every buy-in and marks it cancelled; otherwise it is marked cancelled here.
+// Running tournaments are terminated through their control endpoint.
+func cancelTournament(c client.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tournament, ok := loadTournament(w, r)
+		if !ok {
+			return
+		}
+		if tournament.Cancelled {
+			writeJSON(w, http.StatusOK, tournament)
+			return
+		}
+		if tournament.Ongoing {
+			writeError(w, http.StatusConflict, fmt.Errorf("tournament %d is running, terminate it instead", tournament.ID))
+			return
+		}
+
+		if err := temporal.UnscheduleTournament(r.Context(), c, tournament.ID); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		req := temporal.ControlRequest{Action: temporal.ControlTerminate, Reason: "tournament cancelled"}
+		err := c.SignalWorkflow(r.Context(), temporal.TournamentWorkflowID(tournament.ID), "", temporal.ControlSignal, req)
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			if err := db.GetDB().Model(&tournament).Update("cancelled", true).Error; err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, tournament)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func validateTournament(tournament models.Tournament) error {
+	if tournament.Name == "" {
+		return fmt.Errorf("tournament name is required")
+	}
+	if tournament.MinPlayers < 2 || (tournament.MaxPlayers != 0 && tournament.MaxPlayers < tournament.MinPlayers) {
+		return fmt.Errorf("invalid player limits %d-%d", tournament.MinPlayers, tournament.MaxPlayers)
+	}
+	if tournament.TableSize != 0 && !poker.ValidTableSize(tournament.TableSize) {
+		return fmt.Errorf("invalid table size %d", tournament.TableSize)
+	}
+	if tournament.TurnSeconds <= 0 {
+		return fmt.Errorf("invalid turn time %d seconds", tournament.TurnSeconds)
+	}
+	if err := tournament.Configuration.Blinds.Validate(); err != nil {
+		return err
+	}
+	if tournament.Configuration.SitAndGo {
+		return nil
+	}
+	if tournament.RegistrationStartDate.IsZero() || tournament.StartDate.IsZero() {
+		return fmt.Errorf("registration start and start dates are required")
+	}
+	if !tournament.RegistrationEndDate.After(tournament.RegistrationStartDate) {
+		return fmt.Errorf("registration must end after it starts")
+	}
+	if !tournament.StartDate.After(tournament.RegistrationStartDate) {
+		return fmt.Errorf("tournament must start after registration opens")
+	}
+	return nil
+}
+
+// openSitAndGo opens registration for a sit-and-go tournament.
+func openSitAndGo(cfg *config.Config, c client.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tournament, ok := loadTournament(w, r)
+		if !ok {
+			return
+		}
+		if !tournament.Configuration.SitAndGo {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("tournament %d is not a sit-and-go", tournament.ID))
+			return
+		}
+
+		_, err := c.ExecuteWorkflow(r.Context(), client.StartWorkflowOptions{
+			ID:        temporal.TournamentWorkflowID(tournament.ID),
+			TaskQueue: temporal.TaskQueue,
+		}, temporal.SitAndGoWorkflow, tournament, cfg)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, tournament)
+	}
+}
+
+// loadTournament reads the tournament named in the URL, writing the error
+// response when it cannot.
+func loadTournament(w http.ResponseWriter, r *http.Request) (models.Tournament, bool) {
+	var tournament models.Tournament
+	err := db.GetDB().First(&tournament, mux.Vars(r)["id"]).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("tournament %s not found", mux.Vars(r)["id"]))
+		return tournament, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return tournament, false
+	}
+	return tournament, true
+}
+
+// signalTournament forwards a late entry, rebuy, add-on, deal proposal or
+// control request to the running tournament workflow.
+func signalTournament(c client.Client, signalName string, newRequest func() interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid tournament id %q", mux.Vars(r)["id"]))
+			return
+		}
+		req := newRequest()
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		err = c.SignalWorkflow(r.Context(), temporal.TournamentWorkflowID(uint(id)), "", signalName, req)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// getTournamentPayouts returns the prize ladder for the current entries,
+// rebuys and add-ons, or for the field size given in the entries query
+// parameter.
+func getTournamentPayouts(w http.ResponseWriter, r *http.Request) {
+	tournament, ok := loadTournament(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	var totals struct {
+		Entries int
+		Rebuys  int
+		AddOns  int
+	}
+	if value := r.URL.Query().Get("entries"); value != "" {
+		totals.Entries, err = strconv.Atoi(value)
+		if err != nil || totals.Entries < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid entries %q", value))
+			return
+		}
+	} else {
+		err := db.GetDB().Model(&models.TournamentRegistration{}).
+			Select("COUNT(*) AS entries, COALESCE(SUM(rebuys), 0) AS rebuys, COUNT(*) FILTER (WHERE add_on) AS add_ons").
+			Where("tournament_id = ?", tournament.ID).
+			Scan(&totals).Error
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	pool, err := poker.CalculatePrizePool(tournament.Configuration, totals.Entries, totals.Rebuys, totals.AddOns)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pool)
+}
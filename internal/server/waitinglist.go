@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"server/config"
+	"server/internal/poker"
+	temporal "server/internal/workflow"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+)
+
+func registerWaitingListRoutes(r *mux.Router, cfg *config.Config, c client.Client) {
+	for _, prefix := range []string{"/cash-tables/{id}/waiting-list", "/stakes/{stake}/waiting-list"} {
+		r.HandleFunc(prefix, getWaitingList(c)).Methods(http.MethodGet)
+		r.HandleFunc(prefix+"/join", joinWaitingList(cfg, c)).Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/leave", signalWaitingList(c, temporal.LeaveWaitingListSignal, func() interface{} { return &temporal.LeaveWaitingListRequest{} })).Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/accept", signalWaitingList(c, temporal.AcceptSeatSignal, func() interface{} { return &temporal.AcceptSeatRequest{} })).Methods(http.MethodPost)
+		r.HandleFunc(prefix+"/decline", signalWaitingList(c, temporal.DeclineSeatSignal, func() interface{} { return &temporal.DeclineSeatRequest{} })).Methods(http.MethodPost)
+	}
+}
+
+// waitingListKey returns the key of the list addressed by the route, either a
+// single table or a stake written as "<small blind>-<big blind>".
+func waitingListKey(r *http.Request) (string, error) {
+	vars := mux.Vars(r)
+	if tableID, ok := vars["id"]; ok {
+		return poker.TableWaitingListKey(tableID), nil
+	}
+	var smallBlind, bigBlind int
+	if _, err := fmt.Sscanf(vars["stake"], "%d-%d", &smallBlind, &bigBlind); err != nil {
+		return "", fmt.Errorf("invalid stake %q, expected <small blind>-<big blind>", vars["stake"])
+	}
+	return poker.StakeWaitingListKey(smallBlind, bigBlind), nil
+}
+
+func getWaitingList(c client.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, err := waitingListKey(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		list := poker.WaitingList{Key: key, Entries: []poker.WaitingEntry{}, Offers: []poker.SeatOffer{}}
+		value, err := c.QueryWorkflow(r.Context(), temporal.WaitingListWorkflowID(key), "", temporal.WaitingListStateQuery)
+		if err != nil {
+			var notFound *serviceerror.NotFound
+			if errors.As(err, &notFound) {
+				// Nobody is waiting, so the list workflow is not running.
+				writeJSON(w, http.StatusOK, list)
+				return
+			}
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if err := value.Get(&list); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, list)
+	}
+}
+
+func joinWaitingList(cfg *config.Config, c client.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, err := waitingListKey(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		var entry poker.WaitingEntry
+		if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		reservation := time.Duration(cfg.WaitingList.ReservationSeconds) * time.Second
+		_, err = c.SignalWithStartWorkflow(r.Context(), temporal.WaitingListWorkflowID(key), temporal.JoinWaitingListSignal, entry,
+			client.StartWorkflowOptions{TaskQueue: temporal.TaskQueue},
+			temporal.WaitingListWorkflow, poker.WaitingList{Key: key}, reservation)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeJSON(w, http.StatusAccepted, entry)
+	}
+}
+
+func signalWaitingList(c client.Client, signalName string, newRequest func() interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key, err := waitingListKey(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		req := newRequest()
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := c.SignalWorkflow(r.Context(), temporal.WaitingListWorkflowID(key), "", signalName, req); err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		writeJSON(w, http.StatusAccepted, req)
+	}
+}
@@ -2,29 +2,51 @@ package temporal
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 	"server/config"
+	"server/internal/db"
+	"server/internal/db/models"
 	"server/internal/poker"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"go.temporal.io/sdk/activity"
+	sdktemporal "go.temporal.io/sdk/temporal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func DealCardsActivity(ctx context.Context, table *poker.Table, config *config.Config) (*poker.Table, error) {
+// activityKey identifies the current activity across its retries. It keys
+// wallet operations and, as a JetStream message ID, makes a retried publish a
+// duplicate the stream drops.
+func activityKey(ctx context.Context) string {
+	info := activity.GetInfo(ctx)
+	return fmt.Sprintf("%s/%s/%s", info.WorkflowExecution.ID, info.WorkflowExecution.RunID, info.ActivityID)
+}
+
+func msgID(ctx context.Context, parts ...string) nats.PubOpt {
+	return nats.MsgId(strings.Join(append([]string{activityKey(ctx)}, parts...), "/"))
+}
+
+// DealCardsActivity deals the hand from deck, shuffled by the workflow, and
+// sends every player their hole cards.
+func DealCardsActivity(ctx context.Context, table *poker.Table, deck []poker.Card, config *config.Config) (*poker.Table, error) {
 	log.Printf("Starting DealCardsActivity with table ID: %s", table.ID)
-	table.DealCards()
+	if len(deck) == 0 {
+		return nil, sdktemporal.NewNonRetryableApplicationError(fmt.Sprintf("no deck to deal table %s", table.ID), InvalidPayloadError, nil)
+	}
+	table.DealFrom(deck)
 
 	js := GetJetStream()
-	for _, player := range table.Players {
-		if err := poker.SendPlayerUpdateToNATS(js, table.ID, player); err != nil {
-			log.Printf("Error sending player update to NATS for player ID %s: %v", player.ID, err)
-			continue
+	for _, player := range table.SeatedPlayers() {
+		if err := poker.SendPlayerUpdateToNATS(js, table.ID, *player, msgID(ctx, player.ID)); err != nil {
+			return nil, publishError(fmt.Errorf("failed to send cards to player %s: %w", player.ID, err))
 		}
+		activity.RecordHeartbeat(ctx, player.ID)
 	}
-	time.Sleep(2 * time.Second)
 
 	log.Printf("Completed DealCardsActivity for table ID: %s", table.ID)
 	return table, nil
@@ -32,284 +54,344 @@ func DealCardsActivity(ctx context.Context, table *poker.Table, config *config.C
 
 func DealPreFlop(ctx context.Context, table *poker.Table, config *config.Config) (*poker.Table, error) {
 	table.CurrentStage = "initRound"
+	table.VoidReason = ""
 	table.SMBBTurn()
 	table.RemovePlayersEliminatedWithNoChips()
-	if len(table.Players) < 2 {
+	if table.GameType == poker.GameCash {
+		table.PrepareCashHand()
+	}
+	table.RecordStartingStacks()
+	table.Pots = nil
+	table.History = nil
+	table.Rake = 0
+	if len(table.DealtPlayers()) < 2 {
 		table.CurrentStage = "finishTable"
+	} else {
+		table.HandNumber++
 	}
 	js := GetJetStream()
-	err := poker.SendPTableUpdateToNATS(js, table)
+	err := poker.SendPTableUpdateToNATS(js, table, msgID(ctx))
 	if err != nil {
-		log.Fatalf("Failed to Send Data To Table: %v", err)
+		return nil, publishError(fmt.Errorf("failed to send table %s update: %w", table.ID, err))
 	}
 
-	time.Sleep(2 * time.Second)
-
 	return table, nil
 }
 
 func DealFlop(ctx context.Context, table *poker.Table, config *config.Config) (*poker.Table, error) {
 	table.CurrentStage = "flop"
-	time.Sleep(2 * time.Second)
-
 	return table, nil
 }
 
 func DealTurn(ctx context.Context, table *poker.Table, config *config.Config) (*poker.Table, error) {
 	table.CurrentStage = "turn"
-	time.Sleep(2 * time.Second)
-
 	return table, nil
 }
 
 func DealRiver(ctx context.Context, table *poker.Table, config *config.Config) (*poker.Table, error) {
 	table.CurrentStage = "river"
-	time.Sleep(2 * time.Second)
+	return table, nil
+}
+
+func ShowDown(ctx context.Context, table *poker.Table, config *config.Config) (*poker.Table, error) {
+	js := GetJetStream()
+	table.EvaluateHand()
+	table.SettlePots()
+
+	table.CurrentStage = "ShowDown"
+
+	err := poker.SendPTableUpdateToNATS(js, table, msgID(ctx))
+	if err != nil {
+		return nil, publishError(fmt.Errorf("Error enviando actualización a JetStream para el jugador: %w", err))
+	}
+
+	table.ClearPlayerActions()
+	table.ClearTableActions()
+	table.SetEliminatePlayersWithNoChips()
+
+	log.Printf("El jugador %s ha ganado la mano con %s", table.Winners[0].ID, table.Winners[0].HandDescription)
+
+	return table, nil
+}
+
+func ShowDownAllFoldExecptOne(ctx context.Context, table *poker.Table, config *config.Config) (*poker.Table, error) {
+	js := GetJetStream()
+	table.CurrentStage = "ShowDownAllFoldExceptOne"
+	table.SettlePots()
+	err := poker.SendPTableUpdateToNATS(js, table, msgID(ctx))
+	if err != nil {
+		return nil, publishError(fmt.Errorf("Error enviando actualización a JetStream para el jugador: %w", err))
+	}
+	table.ClearPlayerActions()
+	table.ClearTableActions()
+	table.SetEliminatePlayersWithNoChips()
+
+	log.Printf("El jugador %s ha ganado la mano con %s", table.Winners[0].ID, table.Winners[0].HandDescription)
 
 	return table, nil
 }
 
-func HandleTurns(ctx context.Context, table *poker.Table) (*poker.Table, error) {
+func VoidHandActivity(ctx context.Context, table *poker.Table, reason string) (*poker.Table, error) {
 	js := GetJetStream()
-	table.LastToRaiserIndex = -1
-	bbIndex := -1
+	table.VoidHand(reason)
 
-	for i, player := range table.Players {
-		if player.ID == table.CurrentBB {
-			bbIndex = i
-			break
-		}
+	err := poker.SendPTableUpdateToNATS(js, table, msgID(ctx))
+	if err != nil {
+		return nil, publishError(fmt.Errorf("failed to send void hand update for table %s: %w", table.ID, err))
 	}
 
-	if bbIndex == -1 {
-		return nil, fmt.Errorf("No se encontró el jugador con Big Blind en la mesa")
+	log.Printf("Hand voided on table %s: %s", table.ID, reason)
+
+	return table, nil
+}
+
+func BuyInActivity(ctx context.Context, walletID uint, amount int) error {
+	if err := db.ApplyWalletOperation(GetDB(), activityKey(ctx), walletID, -amount); err != nil {
+		return dbError(err)
 	}
+	log.Printf("Wallet %d bought in for %d", walletID, amount)
+	return nil
+}
 
-	startingPlayerIndex := -1
+func CashOutActivity(ctx context.Context, walletID uint, amount int) error {
+	if amount <= 0 {
+		return nil
+	}
+	if err := db.ApplyWalletOperation(GetDB(), activityKey(ctx), walletID, amount); err != nil {
+		return dbError(err)
+	}
+	log.Printf("Wallet %d cashed out %d", walletID, amount)
+	return nil
+}
 
-	if table.CurrentStage == "preFlop" {
-		table.SetSMBB()
-		startingPlayerIndex = (bbIndex + 1) % len(table.Players)
-	} else {
-		for i := 1; i < len(table.Players); i++ {
-			currentIndex := (bbIndex + i) % len(table.Players)
-			if !table.Players[currentIndex].HasFold && !table.Players[currentIndex].HasAllIn && !table.Players[currentIndex].IsEliminated {
-				startingPlayerIndex = currentIndex
-				break
-			}
+func RecordRakeActivity(ctx context.Context, tableID string, handNumber int, pots []poker.Pot) error {
+	records := []models.RakeRecord{}
+	for i, pot := range pots {
+		if pot.Rake == 0 {
+			continue
 		}
+		records = append(records, models.RakeRecord{
+			TableID:    tableID,
+			HandNumber: handNumber,
+			PotIndex:   i,
+			PotAmount:  pot.Amount,
+			Rake:       pot.Rake,
+		})
+	}
+	if len(records) == 0 {
+		return nil
 	}
 
-	if table.AllPlayersAllInExceptOneAndFolded() || table.AllPlayersAllInExceptFolded() {
-		return table, nil
+	if err := GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&records).Error; err != nil {
+		return dbError(fmt.Errorf("failed to record rake for table %s hand %d: %w", tableID, handNumber, err))
 	}
+	return nil
+}
+
+// SendTableUpdateActivity publishes the table as it is now, for example when
+// the turn moves to another player.
+func SendTableUpdateActivity(ctx context.Context, table *poker.Table) error {
+	return publishError(poker.SendPTableUpdateToNATS(GetJetStream(), table, msgID(ctx)))
+}
 
-	if startingPlayerIndex == -1 {
-		return nil, fmt.Errorf("No se encontró un jugador válido para iniciar la ronda")
+func PublishEventActivity(ctx context.Context, subject string, event poker.TableEvent) error {
+	return publishError(poker.SendEventToNATS(GetJetStream(), subject, event, msgID(ctx)))
+}
+
+// StartTournamentActivity marks the tournament as ongoing and returns its
+// registered players.
+func StartTournamentActivity(ctx context.Context, tournamentID uint) ([]poker.Player, error) {
+	var registrations []models.TournamentRegistration
+	if err := GetDB().Where("tournament_id = ?", tournamentID).Order("id").Find(&registrations).Error; err != nil {
+		return nil, dbError(fmt.Errorf("failed to load registrations of tournament %d: %w", tournamentID, err))
 	}
 
-	currentIndex := startingPlayerIndex
-	var raiseOccurred bool
+	players := make([]poker.Player, 0, len(registrations))
+	for _, registration := range registrations {
+		players = append(players, poker.Player{
+			ID:       strconv.FormatUint(uint64(registration.WalletID), 10),
+			WalletID: registration.WalletID,
+			Entry:    max(registration.Entry, 1),
+		})
+	}
 
-	for {
-		player := &table.Players[currentIndex]
+	err := GetDB().Model(&models.Tournament{}).Where("id = ?", tournamentID).Update("ongoing", true).Error
+	if err != nil {
+		return nil, dbError(fmt.Errorf("failed to start tournament %d: %w", tournamentID, err))
+	}
+	return players, nil
+}
 
-		if player.HasFold || player.HasAllIn || player.IsEliminated {
-			currentIndex = (currentIndex + 1) % len(table.Players)
-			if currentIndex == startingPlayerIndex && !raiseOccurred && table.PlayerActedInRound >= table.CountActivePlayers() {
-				break
+// SaveSeatingActivity stores the tables of a tournament and who sits where.
+func SaveSeatingActivity(ctx context.Context, tournamentID uint, tables []poker.Table) error {
+	err := GetDB().Transaction(func(tx *gorm.DB) error {
+		for _, table := range tables {
+			activity.RecordHeartbeat(ctx, table.ID)
+			row := models.Table{TournamentID: tournamentID, TableNumber: table.Number}
+			if err := tx.Where(row).FirstOrCreate(&row).Error; err != nil {
+				return fmt.Errorf("failed to save table %s: %w", table.ID, err)
+			}
+			if err := tx.Where("table_id = ?", row.ID).Delete(&models.TablePlayer{}).Error; err != nil {
+				return fmt.Errorf("failed to clear seats of table %s: %w", table.ID, err)
+			}
+			for _, player := range table.SeatedPlayers() {
+				seat := models.TablePlayer{TableID: row.ID, WalletID: player.WalletID, Seat: player.Seat}
+				if err := tx.Create(&seat).Error; err != nil {
+					return fmt.Errorf("failed to seat wallet %d on table %s: %w", player.WalletID, table.ID, err)
+				}
 			}
-			continue
 		}
+		return nil
+	})
+	return dbError(err)
+}
 
-		table.CurrentTurn = player.ID
-		table.EndTime = int(time.Now().Unix()) + table.TurnTime
-		table.SetTablePlayerActions(currentIndex)
+// SaveEntryActivity stores a tournament entry taken or changed after the
+// start, keyed by wallet and entry number.
+func SaveEntryActivity(ctx context.Context, tournamentID uint, entry poker.TournamentEntry) error {
+	registration := models.TournamentRegistration{TournamentID: tournamentID, WalletID: entry.WalletID, Entry: entry.Entry}
+	err := GetDB().Where(registration).
+		Assign(map[string]interface{}{"chips": entry.Chips, "rebuys": entry.Rebuys, "add_on": entry.AddOn}).
+		FirstOrCreate(&registration).Error
+	if err != nil {
+		return dbError(fmt.Errorf("failed to save entry %d of wallet %d in tournament %d: %w", entry.Entry, entry.WalletID, tournamentID, err))
+	}
+	return nil
+}
 
-		err := poker.SendPTableUpdateToNATS(js, table)
-		if err != nil {
-			return nil, fmt.Errorf("Error enviando actualización a JetStream para el jugador: %v", err)
+// CancelTournamentActivity refunds the buy-in of every registered wallet and
+// marks the tournament cancelled. A tournament already cancelled is left
+// untouched, so a retry never refunds twice.
+func CancelTournamentActivity(ctx context.Context, tournamentID uint, walletIDs []uint, buyIn int) error {
+	err := GetDB().Transaction(func(tx *gorm.DB) error {
+		var tournament models.Tournament
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tournament, tournamentID).Error; err != nil {
+			return fmt.Errorf("failed to load tournament %d: %w", tournamentID, err)
 		}
-
-		log.Printf("El turno es para el jugador %s", table.CurrentTurn)
-
-		subject := fmt.Sprintf("pokerClient.tournament.%s.%s", table.ID, player.ID)
-		consumerName := fmt.Sprintf("durable-consumer4-%s-%s", table.ID, player.ID)
-		msgChan := make(chan *nats.Msg, 64)
-
-		err = js.DeleteConsumer("POKER_TOURNAMENT", consumerName)
-		if err != nil && !errors.Is(err, nats.ErrConsumerNotFound) {
-			return nil, fmt.Errorf("Error eliminando el consumidor %s: %v", consumerName, err)
+		if tournament.Cancelled {
+			return nil
 		}
-
-		sub, err := js.ChanSubscribe(subject, msgChan, nats.Durable(consumerName), nats.AckExplicit(), nats.DeliverAll())
-		if err != nil {
-			return nil, fmt.Errorf("Error suscribiéndose a JetStream subject %s: %v", subject, err)
+		for _, walletID := range walletIDs {
+			activity.RecordHeartbeat(ctx, walletID)
+			if err := db.CreditWallet(tx, walletID, buyIn); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&tournament).Update("cancelled", true).Error; err != nil {
+			return fmt.Errorf("failed to cancel tournament %d: %w", tournamentID, err)
 		}
-		defer func() {
-			if err := sub.Unsubscribe(); err != nil {
-				log.Printf("Error desuscribiendo del subject %s: %v", subject, err)
+		return nil
+	})
+	return dbError(err)
+}
+
+// MoveSeatsActivity moves the seats of players sent to another table.
+func MoveSeatsActivity(ctx context.Context, tournamentID uint, moves []poker.SeatMove) error {
+	err := GetDB().Transaction(func(tx *gorm.DB) error {
+		tableIDs := tx.Model(&models.Table{}).Select("id").Where("tournament_id = ?", tournamentID)
+		for _, move := range moves {
+			var table models.Table
+			err := tx.Where("tournament_id = ? AND table_number = ?", tournamentID, move.ToTableNumber).First(&table).Error
+			if err != nil {
+				return fmt.Errorf("failed to find table %s: %w", move.ToTable, err)
 			}
-		}()
-
-		select {
-		case msg := <-msgChan:
-			player.IsTurn = false
-			var action poker.Player
-			if err := json.Unmarshal(msg.Data, &action); err != nil {
-				log.Printf("Error al deserializar mensaje: %v", err)
-				continue
+			err = tx.Model(&models.TablePlayer{}).
+				Where("wallet_id = ? AND table_id IN (?)", move.WalletID, tableIDs).
+				Updates(map[string]interface{}{"table_id": table.ID, "seat": move.Seat}).Error
+			if err != nil {
+				return fmt.Errorf("failed to move wallet %d to table %s: %w", move.WalletID, move.ToTable, err)
 			}
+		}
+		return nil
+	})
+	return dbError(err)
+}
 
-			if err := msg.Ack(); err != nil {
-				log.Printf("Error al marcar el mensaje como leído: %v", err)
-				break
+// RecordEliminationsActivity stores the finishing position of busted
+// entries. A ranking with no position removes it, for a player who rebought.
+func RecordEliminationsActivity(ctx context.Context, tournamentID uint, rankings []models.Ranking) error {
+	err := GetDB().Transaction(func(tx *gorm.DB) error {
+		for _, ranking := range rankings {
+			err := tx.Where("tournament_id = ? AND wallet_id = ? AND entry = ?", tournamentID, ranking.WalletID, ranking.Entry).
+				Delete(&models.Ranking{}).Error
+			if err != nil {
+				return fmt.Errorf("failed to clear ranking of wallet %d: %w", ranking.WalletID, err)
 			}
-
-			player.LastAction = action.LastAction
-
-			switch action.LastAction {
-			case "raise":
-				raiseOccurred = true
-				table.LastToRaiserIndex = currentIndex
-				startingPlayerIndex = currentIndex
-				table.Players[currentIndex].TotalBet += action.LastBet
-				table.Players[currentIndex].Chips -= action.LastBet
-				table.BiggestBet = table.Players[currentIndex].TotalBet
-				table.Players[currentIndex].CallAmount -= action.LastBet
-				table.TotalBet += action.LastBet
-				table.SetTablePlayersCallAmount()
-
-				table.PlayerActedInRound = 1
-				for i := range table.Players {
-					if table.Players[i].ID != player.ID && !table.Players[i].HasFold && !table.Players[i].HasAllIn {
-						table.Players[i].LastAction = ""
-					}
-				}
-
-			case "fold":
-				table.PlayerActedInRound++
-				player.HasFold = true
-			case "call":
-				table.Players[currentIndex].TotalBet += action.LastBet
-				table.Players[currentIndex].Chips -= action.LastBet
-				table.Players[currentIndex].CallAmount -= action.LastBet
-				table.TotalBet += action.LastBet
-				table.Players[currentIndex].HasFold = false
-				table.PlayerActedInRound++
-			case "allin":
-				table.Players[currentIndex].HasAllIn = true
-				table.Players[currentIndex].TotalBet += table.Players[currentIndex].Chips
-				table.TotalBet += table.Players[currentIndex].Chips
-				table.PlayerActedInRound++
-				if table.Players[currentIndex].Chips > table.Players[currentIndex].CallAmount {
-					table.BiggestBet = table.Players[currentIndex].TotalBet
-					raiseOccurred = true
-					table.LastToRaiserIndex = currentIndex
-					startingPlayerIndex = currentIndex
-					table.PlayerActedInRound = 1
-					for i := range table.Players {
-						if table.Players[i].ID != player.ID && !table.Players[i].HasFold && !table.Players[i].HasAllIn {
-							table.Players[i].LastAction = ""
-						}
-					}
-					table.SetTablePlayersCallAmount()
-				}
-				table.Players[currentIndex].CallAmount -= table.Players[currentIndex].Chips
-				table.Players[currentIndex].Chips = 0
-			case "check":
-				table.PlayerActedInRound++
+			if ranking.Position == 0 {
+				continue
 			}
-		case <-time.After(time.Duration(table.TurnTime) * time.Second):
-			player.IsTurn = false
-			log.Printf("El tiempo de turno para el jugador %s ha expirado", player.ID)
-			player.LastAction = "fold"
-			player.HasFold = true
-			if player.CallAmount <= 0 {
-				player.LastAction = "check"
-				player.HasFold = false
+			if err := tx.Create(&ranking).Error; err != nil {
+				return fmt.Errorf("failed to rank wallet %d at %d: %w", ranking.WalletID, ranking.Position, err)
 			}
-			table.PlayerActedInRound++
-		case <-ctx.Done():
-			return nil, ctx.Err()
 		}
+		return nil
+	})
+	return dbError(err)
+}
 
-		table.AllPlayersExceptOneFold()
-
-		currentIndex = (currentIndex + 1) % len(table.Players)
-
-		if table.AllPlayersAllInExceptFolded() {
-			break
+// FinishTournamentActivity writes the final ranking, credits the prizes and
+// bounties won and closes the tournament. A tournament already closed is left untouched, so a
+// retry never pays twice.
+func FinishTournamentActivity(ctx context.Context, tournamentID uint, pool poker.PrizePool, rankings []models.Ranking) error {
+	err := GetDB().Transaction(func(tx *gorm.DB) error {
+		var tournament models.Tournament
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tournament, tournamentID).Error; err != nil {
+			return fmt.Errorf("failed to load tournament %d: %w", tournamentID, err)
 		}
-
-		if table.AllFoldExceptOne {
-			break
+		if !tournament.Ongoing {
+			return nil
 		}
 
-		if currentIndex == table.LastToRaiserIndex && !raiseOccurred {
-			break
+		if err := tx.Where("tournament_id = ?", tournamentID).Delete(&models.Ranking{}).Error; err != nil {
+			return fmt.Errorf("failed to clear ranking of tournament %d: %w", tournamentID, err)
 		}
-
-		if currentIndex == startingPlayerIndex && !raiseOccurred {
-			break
+		if len(rankings) > 0 {
+			if err := tx.Create(&rankings).Error; err != nil {
+				return fmt.Errorf("failed to save ranking of tournament %d: %w", tournamentID, err)
+			}
 		}
-
-		if raiseOccurred {
-			table.PlayerActedInRound = 1
-			raiseOccurred = false
+		for _, ranking := range rankings {
+			activity.RecordHeartbeat(ctx, ranking.WalletID)
+			err := tx.Model(&models.TournamentRegistration{}).
+				Where("tournament_id = ? AND wallet_id = ? AND entry = ?", tournamentID, ranking.WalletID, ranking.Entry).
+				Update("bounties_won", ranking.Bounties).Error
+			if err != nil {
+				return fmt.Errorf("failed to save bounties of wallet %d: %w", ranking.WalletID, err)
+			}
+			if ranking.Prize+ranking.Bounties == 0 {
+				continue
+			}
+			if err := db.CreditWallet(tx, ranking.WalletID, ranking.Prize+ranking.Bounties); err != nil {
+				return err
+			}
 		}
-
-		if table.AllFoldExceptOne || (table.PlayerActedInRound == len(table.Players)) {
-			break
+		err := tx.Model(&models.Tournament{}).Where("id = ?", tournamentID).
+			Updates(map[string]interface{}{
+				"ongoing":    false,
+				"end_date":   time.Now(),
+				"prize_pool": pool.Total,
+				"overlay":    pool.Overlay,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("failed to close tournament %d: %w", tournamentID, err)
 		}
-	}
-
-	// Limpiar el estado de IsTurn de todos los jugadores
-	for i := range table.Players {
-		table.Players[i].IsTurn = false
-	}
-	table.PlayerActedInRound = 0
-
-	log.Printf("Los turnos de los jugadores se han completado para la mesa ID: %s", table.ID)
-	return table, nil
+		return nil
+	})
+	return dbError(err)
 }
 
-func ShowDown(ctx context.Context, table *poker.Table, config *config.Config) (*poker.Table, error) {
-	js := GetJetStream()
-	table.EvaluateHand()
-	table.AssignChipsToWinner(&table.Winners[0])
-
-	table.CurrentStage = "ShowDown"
-
-	err := poker.SendPTableUpdateToNATS(js, table)
+// CurrentBlindLevelActivity reads the level in play from the tournament clock.
+func CurrentBlindLevelActivity(ctx context.Context, tournamentID string) (poker.ClockState, error) {
+	var state poker.ClockState
+	resp, err := GetTemporalClient().QueryWorkflow(ctx, TournamentClockWorkflowID(tournamentID), "", ClockStateQuery)
 	if err != nil {
-		return nil, fmt.Errorf("Error enviando actualización a JetStream para el jugador: %v", err)
+		return state, queryError(fmt.Errorf("failed to query clock of tournament %s: %w", tournamentID, err))
 	}
-
-	table.ClearPlayerActions()
-	table.ClearTableActions()
-	table.SetEliminatePlayersWithNoChips()
-
-	log.Printf("El jugador %s ha ganado la mano con %s", table.Winners[0].ID, table.Winners[0].HandDescription)
-
-	return table, nil
-}
-
-func ShowDownAllFoldExecptOne(ctx context.Context, table *poker.Table, config *config.Config) (*poker.Table, error) {
-	js := GetJetStream()
-	table.CurrentStage = "ShowDownAllFoldExceptOne"
-	table.AssignChipsToWinner(&table.Winners[0])
-	err := poker.SendPTableUpdateToNATS(js, table)
-	if err != nil {
-		return nil, fmt.Errorf("Error enviando actualización a JetStream para el jugador: %v", err)
+	if err := resp.Get(&state); err != nil {
+		err = fmt.Errorf("failed to decode clock of tournament %s: %w", tournamentID, err)
+		return state, sdktemporal.NewNonRetryableApplicationError(err.Error(), InvalidPayloadError, err)
 	}
-	table.ClearPlayerActions()
-	table.ClearTableActions()
-	table.SetEliminatePlayersWithNoChips()
-
-	log.Printf("El jugador %s ha ganado la mano con %s", table.Winners[0].ID, table.Winners[0].HandDescription)
-
-	return table, nil
+	return state, nil
 }
 
 type MessageResult struct {
@@ -0,0 +1,218 @@
+package temporal
+
+import (
+	"fmt"
+	"server/internal/poker"
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// ControlSignal carries a ControlRequest from the tournament staff. It is
+// accepted by the workflow that owns a table, TableWorkflowID, by the
+// tournament workflow and by the tournament clock.
+const ControlSignal = "Control"
+
+const (
+	ControlPause     = "pause"
+	ControlResume    = "resume"
+	ControlTerminate = "terminate"
+	ControlMove      = "move"
+)
+
+// ControlRequest pauses, resumes or ends a table or tournament. A pause takes
+// effect once the hand in play is over unless Immediate is set, in which case
+// the turn timer is frozen right away. A table is only terminated between
+// hands, or at once while frozen, voiding the hand so every stack is kept.
+//
+// A move drains a table to Shard: once the hand in play is over the table
+// continues as new on the task queue of that shard.
+type ControlRequest struct {
+	Action    string
+	Reason    string
+	Immediate bool
+	Shard     string
+}
+
+// applyControl applies a request received between hands, or during a hand
+// whose turn timer is running.
+func applyControl(ctx workflow.Context, table *poker.Table, req ControlRequest) {
+	switch req.Action {
+	case ControlPause:
+		if table.PausedReason != "" {
+			return
+		}
+		table.PausedReason = controlReason(req, "paused by admin")
+		announceControl(ctx, table, "tablePaused", table.PausedReason)
+	case ControlResume:
+		if table.PausedReason == "" {
+			return
+		}
+		table.PausedReason = ""
+		announceControl(ctx, table, "tableResumed", req.Reason)
+	case ControlTerminate:
+		table.TerminateReason = controlReason(req, "terminated by admin")
+		announceControl(ctx, table, "tableTerminating", table.TerminateReason)
+	case ControlMove:
+		if req.Shard == "" {
+			workflow.GetLogger(ctx).Warn("Move without shard", "TableID", table.ID)
+			return
+		}
+		table.MoveToShard = req.Shard
+		announceControl(ctx, table, "tableMoving", req.Shard)
+	default:
+		workflow.GetLogger(ctx).Warn("Unknown control action", "TableID", table.ID, "Action", req.Action)
+	}
+}
+
+// drainControl applies every control request received so far.
+func drainControl(ctx workflow.Context, table *poker.Table) {
+	controlCh := workflow.GetSignalChannel(ctx, ControlSignal)
+	for {
+		var req ControlRequest
+		if !controlCh.ReceiveAsync(&req) {
+			return
+		}
+		applyControl(ctx, table, req)
+	}
+}
+
+// awaitResume blocks a paused table until it is resumed or terminated.
+func awaitResume(ctx workflow.Context, table *poker.Table) {
+	controlCh := workflow.GetSignalChannel(ctx, ControlSignal)
+	for table.PausedReason != "" && table.TerminateReason == "" {
+		var req ControlRequest
+		controlCh.Receive(ctx, &req)
+		applyControl(ctx, table, req)
+	}
+}
+
+// freezeTurn stops the turn clock of a hand in play until the table is
+// resumed. It returns the reason to void the hand when the table is
+// terminated or the hand voided in the meantime.
+func freezeTurn(ctx workflow.Context, voidCh, controlCh workflow.ReceiveChannel, table *poker.Table, req ControlRequest) string {
+	table.PausedReason = controlReason(req, "paused by admin")
+	announceControl(ctx, table, "tableFrozen", table.PausedReason)
+
+	var reason string
+	for table.PausedReason != "" && reason == "" {
+		selector := workflow.NewSelector(ctx)
+		selector.AddReceive(controlCh, func(c workflow.ReceiveChannel, _ bool) {
+			var req ControlRequest
+			c.Receive(ctx, &req)
+			switch req.Action {
+			case ControlResume, ControlMove:
+				applyControl(ctx, table, req)
+			case ControlTerminate:
+				table.TerminateReason = controlReason(req, "terminated by admin")
+				reason = fmt.Sprintf("table terminated: %s", table.TerminateReason)
+			}
+		})
+		selector.AddReceive(voidCh, func(c workflow.ReceiveChannel, _ bool) {
+			var req VoidHandRequest
+			c.Receive(ctx, &req)
+			reason = controlReason(ControlRequest{Reason: req.Reason}, "voided by admin")
+		})
+		selector.Select(ctx)
+	}
+	return reason
+}
+
+// pauseTable stops a table that cannot go on by itself, for example because
+// a hand could not be started or voided, and alerts the staff. The workflow
+// that owns the table waits in awaitResume before the next hand.
+func pauseTable(ctx workflow.Context, table poker.Table, reason string) (poker.Table, error) {
+	workflow.GetLogger(ctx).Error("Pausing table", "TableID", table.ID, "Reason", reason)
+	table.PausedReason = reason
+
+	alertAdmins(ctx, table, reason)
+	announceControl(ctx, &table, "tablePaused", reason)
+	return table, nil
+}
+
+// alertAdmins reports a table error to the staff on poker.AdminSubject.
+func alertAdmins(ctx workflow.Context, table poker.Table, reason string) {
+	event := poker.TableEvent{Type: "tableError", TableID: table.ID, Message: reason}
+	publishTableEvent(ctx, poker.AdminSubject(), event)
+}
+
+func announceControl(ctx workflow.Context, table *poker.Table, eventType string, reason string) {
+	publishTableEvent(ctx, poker.TableSubject(table.ID), poker.TableEvent{Type: eventType, TableID: table.ID, Message: reason})
+}
+
+func publishTableEvent(ctx workflow.Context, subject string, event poker.TableEvent) {
+	ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+	if err := workflow.ExecuteActivity(ctx, PublishEventActivity, subject, event).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Error("Failed to publish table event", "TableID", event.TableID, "Event", event.Type, "Error", err)
+	}
+}
+
+// moveTable clears the pending move of table and returns ctx set to continue
+// as new on the task queue of the shard it moves to.
+func moveTable(ctx workflow.Context, table *poker.Table) workflow.Context {
+	shard := table.MoveToShard
+	table.MoveToShard = ""
+	workflow.GetLogger(ctx).Info("Moving table", "TableID", table.ID, "Shard", shard)
+	return workflow.WithWorkflowTaskQueue(ctx, ShardTaskQueue(shard))
+}
+
+func controlReason(req ControlRequest, fallback string) string {
+	if req.Reason == "" {
+		return fallback
+	}
+	return req.Reason
+}
+
+// remainingTurn is what is left of the turn clock of table.
+func remainingTurn(ctx workflow.Context, table *poker.Table) time.Duration {
+	return max(time.Duration(int64(table.EndTime)-workflow.Now(ctx).Unix())*time.Second, 0)
+}
+
+// control pauses, resumes or terminates the whole tournament: every table and
+// the clock get the request too.
+func (t *tournamentRun) control(ctx workflow.Context, req ControlRequest) {
+	var eventType string
+	switch req.Action {
+	case ControlPause:
+		if t.state.PausedReason != "" {
+			return
+		}
+		t.state.PausedReason = controlReason(req, "paused by admin")
+		eventType = "tournamentPaused"
+	case ControlResume:
+		if t.state.PausedReason == "" {
+			return
+		}
+		t.state.PausedReason = ""
+		eventType = "tournamentResumed"
+	case ControlTerminate:
+		if t.state.TerminateReason != "" {
+			return
+		}
+		t.state.TerminateReason = controlReason(req, "terminated by admin")
+		eventType = "tournamentTerminating"
+	default:
+		workflow.GetLogger(ctx).Warn("Unknown control action", "TournamentID", t.tournament.ID, "Action", req.Action)
+		return
+	}
+
+	key := tournamentKey(t.tournament)
+	workflowIDs := []string{TournamentClockWorkflowID(key)}
+	for _, table := range t.state.Tables {
+		workflowIDs = append(workflowIDs, TableWorkflowID(table.Table.ID))
+	}
+	for _, workflowID := range workflowIDs {
+		if err := workflow.SignalExternalWorkflow(ctx, workflowID, "", ControlSignal, req).Get(ctx, nil); err != nil {
+			workflow.GetLogger(ctx).Warn("Failed to forward control request", "WorkflowID", workflowID, "Action", req.Action, "Error", err)
+		}
+	}
+
+	event := poker.TableEvent{Type: eventType, Message: controlReason(req, "")}
+	if err := workflow.ExecuteActivity(ctx, PublishEventActivity, poker.TournamentSubject(key), event).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to announce control request", "TournamentID", t.tournament.ID, "Event", eventType, "Error", err)
+	}
+
+	if req.Action == ControlResume {
+		t.resumeIdleTables(ctx)
+	}
+}
@@ -0,0 +1,322 @@
+package temporal
+
+import (
+	"fmt"
+	"server/config"
+	"server/internal/poker"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	JoinTableSignal  = "JoinTable"
+	TopUpSignal      = "TopUp"
+	LeaveTableSignal = "LeaveTable"
+
+	// cashHandsPerRun bounds the history of a cash table run before it
+	// continues as new.
+	cashHandsPerRun = 50
+)
+
+type JoinTableRequest struct {
+	Player    poker.Player
+	Seat      int // 0 takes the first empty seat
+	BuyIn     int
+	WaitForBB bool
+}
+
+type TopUpRequest struct {
+	PlayerID string
+	Amount   int
+}
+
+type LeaveTableRequest struct {
+	PlayerID string
+}
+
+// TableWorkflowID returns the workflow ID of the long-running workflow that
+// owns a table.
+func TableWorkflowID(tableID string) string {
+	return "table-" + tableID
+}
+
+// CashTableWorkflow deals hands on a cash game table continuously. Joins,
+// top-ups and leaves arrive as signals and are applied between hands. A
+// terminated table cashes everyone out and ends; a moved table continues as
+// new on its new shard.
+func CashTableWorkflow(ctx workflow.Context, table poker.Table, config *config.Config) (poker.Table, error) {
+	ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+	requests := newCashRequests(ctx)
+	if err := registerTableQueries(ctx, &table, nil); err != nil {
+		return table, err
+	}
+
+	for hand := 0; hand < cashHandsPerRun; {
+		requests.drain(ctx, &table)
+		awaitResume(ctx, &table)
+		if table.TerminateReason != "" {
+			return closeCashTable(ctx, table)
+		}
+		if table.MoveToShard != "" {
+			break
+		}
+		if table.ReadyPlayerCount() < 2 {
+			requests.wait(ctx, &table)
+			continue
+		}
+
+		var err error
+		table, err = TableWorkflow(ctx, table, config)
+		if err != nil {
+			return table, err
+		}
+		if err := registerTableQueries(ctx, &table, nil); err != nil {
+			return table, err
+		}
+		if table.PausedReason != "" || table.TerminateReason != "" {
+			continue
+		}
+		if table.CurrentStage == "finishTable" {
+			requests.wait(ctx, &table)
+			continue
+		}
+		hand++
+	}
+
+	requests.drain(ctx, &table)
+	if table.MoveToShard != "" {
+		ctx = moveTable(ctx, &table)
+	}
+	return table, workflow.NewContinueAsNewError(ctx, CashTableWorkflow, table, config)
+}
+
+type cashRequests struct {
+	join    workflow.ReceiveChannel
+	topUp   workflow.ReceiveChannel
+	leave   workflow.ReceiveChannel
+	reserve workflow.ReceiveChannel
+	control workflow.ReceiveChannel
+}
+
+func newCashRequests(ctx workflow.Context) cashRequests {
+	return cashRequests{
+		join:    workflow.GetSignalChannel(ctx, JoinTableSignal),
+		topUp:   workflow.GetSignalChannel(ctx, TopUpSignal),
+		leave:   workflow.GetSignalChannel(ctx, LeaveTableSignal),
+		reserve: workflow.GetSignalChannel(ctx, ReserveSeatSignal),
+		control: workflow.GetSignalChannel(ctx, ControlSignal),
+	}
+}
+
+// drain applies every request received so far without blocking.
+func (r cashRequests) drain(ctx workflow.Context, table *poker.Table) {
+	for {
+		var reserve ReserveSeatRequest
+		if !r.reserve.ReceiveAsync(&reserve) {
+			break
+		}
+		reserveCashSeat(ctx, table, reserve)
+	}
+	for {
+		var join JoinTableRequest
+		if !r.join.ReceiveAsync(&join) {
+			break
+		}
+		joinCashTable(ctx, table, join)
+	}
+	for {
+		var topUp TopUpRequest
+		if !r.topUp.ReceiveAsync(&topUp) {
+			break
+		}
+		topUpCashTable(ctx, table, topUp)
+	}
+	for {
+		var leave LeaveTableRequest
+		if !r.leave.ReceiveAsync(&leave) {
+			break
+		}
+		leaveCashTable(ctx, table, leave)
+	}
+	drainControl(ctx, table)
+}
+
+// wait blocks until at least one request arrives and applies it.
+func (r cashRequests) wait(ctx workflow.Context, table *poker.Table) {
+	selector := workflow.NewSelector(ctx)
+	selector.AddReceive(r.join, func(c workflow.ReceiveChannel, more bool) {
+		var join JoinTableRequest
+		c.Receive(ctx, &join)
+		joinCashTable(ctx, table, join)
+	})
+	selector.AddReceive(r.topUp, func(c workflow.ReceiveChannel, more bool) {
+		var topUp TopUpRequest
+		c.Receive(ctx, &topUp)
+		topUpCashTable(ctx, table, topUp)
+	})
+	selector.AddReceive(r.leave, func(c workflow.ReceiveChannel, more bool) {
+		var leave LeaveTableRequest
+		c.Receive(ctx, &leave)
+		leaveCashTable(ctx, table, leave)
+	})
+	selector.AddReceive(r.control, func(c workflow.ReceiveChannel, more bool) {
+		var req ControlRequest
+		c.Receive(ctx, &req)
+		applyControl(ctx, table, req)
+	})
+	selector.AddReceive(r.reserve, func(c workflow.ReceiveChannel, more bool) {
+		var reserve ReserveSeatRequest
+		c.Receive(ctx, &reserve)
+		reserveCashSeat(ctx, table, reserve)
+	})
+	selector.Select(ctx)
+}
+
+// closeCashTable stands every player up with their chips credited back.
+// Players whose cash out failed stay seated in the returned table, so the
+// staff alerted by leaveCashTable can see what is still owed.
+func closeCashTable(ctx workflow.Context, table poker.Table) (poker.Table, error) {
+	var playerIDs []string
+	for _, player := range table.SeatedPlayers() {
+		playerIDs = append(playerIDs, player.ID)
+	}
+	for _, playerID := range playerIDs {
+		if !leaveCashTable(ctx, &table, LeaveTableRequest{PlayerID: playerID}) {
+			workflow.GetLogger(ctx).Error("Closing table with an unpaid player", "TableID", table.ID, "PlayerID", playerID)
+		}
+	}
+
+	announceControl(ctx, &table, "tableClosed", table.TerminateReason)
+	return table, nil
+}
+
+func joinCashTable(ctx workflow.Context, table *poker.Table, req JoinTableRequest) {
+	if err := table.CheckJoin(req.Seat, req.Player.ID, req.BuyIn); err != nil {
+		rejectCashRequest(ctx, table, req.Player.ID, "joinRejected", err)
+		releaseReservedSeat(ctx, table, req)
+		return
+	}
+	err := workflow.ExecuteActivity(ctx, BuyInActivity, req.Player.WalletID, req.BuyIn).Get(ctx, nil)
+	if err != nil {
+		rejectCashRequest(ctx, table, req.Player.ID, "joinRejected", err)
+		releaseReservedSeat(ctx, table, req)
+		return
+	}
+
+	seat, err := table.JoinCash(req.Seat, req.Player, req.BuyIn, req.WaitForBB)
+	if err != nil {
+		// The seat was validated before the buy-in, so this only happens on a
+		// bug; give the money back rather than keep it.
+		_ = workflow.ExecuteActivity(ctx, CashOutActivity, req.Player.WalletID, req.BuyIn).Get(ctx, nil)
+		rejectCashRequest(ctx, table, req.Player.ID, "joinRejected", err)
+		return
+	}
+
+	notifyTable(ctx, table, poker.TableEvent{
+		Type:     "playerJoined",
+		TableID:  table.ID,
+		PlayerID: req.Player.ID,
+		Data:     map[string]int{"seat": seat, "chips": req.BuyIn},
+	})
+}
+
+func topUpCashTable(ctx workflow.Context, table *poker.Table, req TopUpRequest) {
+	if err := table.CheckTopUp(req.PlayerID, req.Amount); err != nil {
+		rejectCashRequest(ctx, table, req.PlayerID, "topUpRejected", err)
+		return
+	}
+	walletID := table.FindPlayer(req.PlayerID).WalletID
+	if err := workflow.ExecuteActivity(ctx, BuyInActivity, walletID, req.Amount).Get(ctx, nil); err != nil {
+		rejectCashRequest(ctx, table, req.PlayerID, "topUpRejected", err)
+		return
+	}
+
+	_ = table.TopUp(req.PlayerID, req.Amount)
+	notifyTable(ctx, table, poker.TableEvent{
+		Type:     "playerToppedUp",
+		TableID:  table.ID,
+		PlayerID: req.PlayerID,
+		Data:     map[string]int{"chips": table.FindPlayer(req.PlayerID).Chips},
+	})
+}
+
+// leaveCashTable credits a player's chips back to their wallet and only then
+// frees the seat. CashOutActivity is keyed by its activity ID, so retries pay
+// once; if it still fails the player keeps the seat and the staff is alerted.
+func leaveCashTable(ctx workflow.Context, table *poker.Table, req LeaveTableRequest) bool {
+	seated := table.FindPlayer(req.PlayerID)
+	if seated == nil {
+		rejectCashRequest(ctx, table, req.PlayerID, "leaveRejected", fmt.Errorf("player %s is not seated on table %s", req.PlayerID, table.ID))
+		return false
+	}
+
+	ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+	err := workflow.ExecuteActivity(ctx, CashOutActivity, seated.WalletID, seated.Chips).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Error("Cash out failed", "PlayerID", seated.ID, "Chips", seated.Chips, "Error", err)
+		alertAdmins(ctx, *table, fmt.Sprintf("cash out of %d chips for player %s failed: %v", seated.Chips, seated.ID, err))
+		rejectCashRequest(ctx, table, req.PlayerID, "leaveRejected", err)
+		return false
+	}
+
+	player, err := table.LeaveCash(req.PlayerID)
+	if err != nil {
+		return false
+	}
+
+	notifyTable(ctx, table, poker.TableEvent{
+		Type:     "playerLeft",
+		TableID:  table.ID,
+		PlayerID: player.ID,
+		Data:     map[string]int{"seat": player.Seat, "chips": player.Chips},
+	})
+	announceOpenSeat(ctx, table, player.Seat)
+	return true
+}
+
+func reserveCashSeat(ctx workflow.Context, table *poker.Table, req ReserveSeatRequest) {
+	if err := table.ReserveSeat(req.Seat, req.PlayerID); err != nil {
+		workflow.GetLogger(ctx).Warn("Seat reservation ignored", "TableID", table.ID, "Seat", req.Seat, "Error", err)
+	}
+}
+
+// releaseReservedSeat frees a seat held for a waiting list player whose join
+// failed, so the list can offer it to the next in line.
+func releaseReservedSeat(ctx workflow.Context, table *poker.Table, req JoinTableRequest) {
+	if req.Seat == 0 || req.Seat > len(table.Seats) || table.Seats[req.Seat-1].ReservedFor != req.Player.ID {
+		return
+	}
+	table.Seats[req.Seat-1].ReservedFor = ""
+	announceOpenSeat(ctx, table, req.Seat)
+}
+
+// announceOpenSeat hands an empty seat to the table's waiting list. The seat is
+// held until the list releases it or the offered player sits down.
+func announceOpenSeat(ctx workflow.Context, table *poker.Table, seat int) {
+	req := SeatOpenedRequest{TableID: table.ID, Seat: seat, StakeKey: table.StakeKey()}
+	listIDs := []string{
+		WaitingListWorkflowID(poker.TableWaitingListKey(table.ID)),
+		WaitingListWorkflowID(req.StakeKey),
+	}
+	for _, listID := range listIDs {
+		err := workflow.SignalExternalWorkflow(ctx, listID, "", SeatOpenedSignal, req).Get(ctx, nil)
+		if err == nil {
+			table.Seats[seat-1].ReservedFor = "waitingList"
+			return
+		}
+	}
+}
+
+func rejectCashRequest(ctx workflow.Context, table *poker.Table, playerID string, eventType string, err error) {
+	workflow.GetLogger(ctx).Warn("Cash table request rejected", "TableID", table.ID, "PlayerID", playerID, "Error", err)
+
+	event := poker.TableEvent{Type: eventType, TableID: table.ID, PlayerID: playerID, Message: err.Error()}
+	_ = workflow.ExecuteActivity(ctx, PublishEventActivity, poker.PlayerSubject(table.ID, playerID), event).Get(ctx, nil)
+}
+
+func notifyTable(ctx workflow.Context, table *poker.Table, event poker.TableEvent) {
+	err := workflow.ExecuteActivity(ctx, PublishEventActivity, poker.TableSubject(table.ID), event).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to notify table", "TableID", table.ID, "Event", event.Type, "Error", err)
+	}
+}
@@ -0,0 +1,59 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"server/internal/poker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	sdktemporal "go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+// closeCashWorkflow closes a cash table outside of CashTableWorkflow.
+func closeCashWorkflow(ctx workflow.Context, table poker.Table) (poker.Table, error) {
+	return closeCashTable(ctx, table)
+}
+
+func TestCloseCashTableKeepsUnpaidPlayersSeated(t *testing.T) {
+	table, err := poker.NewCashTable("cash", 6, poker.CashConfig{SmallBlind: 1, BigBlind: 2, MinBuyInBB: 20, MaxBuyInBB: 100})
+	require.NoError(t, err)
+	_, err = table.JoinCash(1, poker.Player{ID: "1", WalletID: 1}, 100, false)
+	require.NoError(t, err)
+	_, err = table.JoinCash(2, poker.Player{ID: "2", WalletID: 2}, 150, false)
+	require.NoError(t, err)
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(closeCashWorkflow)
+	env.RegisterActivity(CashOutActivity)
+	env.RegisterActivity(PublishEventActivity)
+	env.OnActivity(CashOutActivity, mock.Anything, uint(1), 100).Return(nil).Once()
+	env.OnActivity(CashOutActivity, mock.Anything, uint(2), 150).
+		Return(sdktemporal.NewNonRetryableApplicationError("wallet 2 not found", NotFoundError, errors.New("not found"))).Once()
+	// No waiting list runs for the table.
+	env.OnSignalExternalWorkflow(mock.Anything, mock.Anything, mock.Anything, SeatOpenedSignal, mock.Anything).Return(errors.New("not found"))
+	var alerts int
+	env.OnActivity(PublishEventActivity, mock.Anything, mock.Anything, mock.Anything).Return(
+		func(_ context.Context, subject string, event poker.TableEvent) error {
+			if subject == poker.AdminSubject() {
+				alerts++
+			}
+			return nil
+		})
+
+	env.ExecuteWorkflow(closeCashWorkflow, table)
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	require.NoError(t, env.GetWorkflowResult(&table))
+
+	assert.Nil(t, table.FindPlayer("1"), "Paid player should leave the table")
+	unpaid := table.FindPlayer("2")
+	require.NotNil(t, unpaid, "Player whose cash out failed should keep the seat")
+	assert.Equal(t, 150, unpaid.Chips)
+	assert.Equal(t, 1, alerts, "Staff should be alerted of the failed cash out")
+}
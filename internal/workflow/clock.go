@@ -0,0 +1,126 @@
+package temporal
+
+import (
+	"server/internal/poker"
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// ClockStateQuery returns the poker.ClockState of a running tournament clock.
+const ClockStateQuery = "ClockState"
+
+func TournamentClockWorkflowID(tournamentID string) string {
+	return "clock-" + tournamentID
+}
+
+// TournamentClockWorkflow runs the blind level at levelIndex in real time,
+// broadcasting the clock every minute, and continues as new with the next
+// level when it ends. The last level never ends; the clock is cancelled when
+// the tournament finishes. A ControlRequest stops and restarts the level
+// timer.
+func TournamentClockWorkflow(ctx workflow.Context, tournamentID string, structure poker.BlindStructure, levelIndex int) error {
+	ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+
+	level := structure.Levels[levelIndex]
+	state := poker.ClockState{
+		TournamentID: tournamentID,
+		LevelIndex:   levelIndex,
+		Level:        level,
+		LevelEndsAt:  workflow.Now(ctx).Add(level.Duration()),
+	}
+	next, hasNext := structure.Next(levelIndex)
+	if hasNext {
+		state.Next = &next
+	}
+
+	var pausedLeft time.Duration
+	remaining := func() time.Duration {
+		if !hasNext {
+			return 0
+		}
+		if state.Paused {
+			return pausedLeft
+		}
+		return max(state.LevelEndsAt.Sub(workflow.Now(ctx)), 0)
+	}
+
+	err := workflow.SetQueryHandler(ctx, ClockStateQuery, func() (poker.ClockState, error) {
+		current := state
+		current.RemainingSeconds = int(remaining().Seconds())
+		return current, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	broadcastClock(ctx, state, remaining(), "blindLevel")
+	if !hasNext {
+		return workflow.Await(ctx, func() bool { return false })
+	}
+
+	controlCh := workflow.GetSignalChannel(ctx, ControlSignal)
+	for left := remaining(); left > 0; left = remaining() {
+		timerCtx, cancelTimer := workflow.WithCancel(ctx)
+		selector := workflow.NewSelector(ctx)
+		selector.AddFuture(workflow.NewTimer(timerCtx, min(left, time.Minute)), func(f workflow.Future) {
+			if remaining() > 0 {
+				broadcastClock(ctx, state, remaining(), "clock")
+			}
+		})
+		selector.AddReceive(controlCh, func(c workflow.ReceiveChannel, more bool) {
+			var req ControlRequest
+			c.Receive(ctx, &req)
+			switch {
+			case req.Action == ControlPause && !state.Paused:
+				pausedLeft = remaining()
+				state.Paused = true
+				broadcastClock(ctx, state, pausedLeft, "clockPaused")
+			case req.Action == ControlResume && state.Paused:
+				state.Paused = false
+				state.LevelEndsAt = workflow.Now(ctx).Add(pausedLeft)
+				broadcastClock(ctx, state, remaining(), "clockResumed")
+			}
+		})
+		selector.Select(ctx)
+		cancelTimer()
+
+		if state.Paused {
+			if err := workflow.Await(ctx, func() bool { return controlCh.Len() > 0 }); err != nil {
+				return err
+			}
+		}
+	}
+
+	return workflow.NewContinueAsNewError(ctx, TournamentClockWorkflow, tournamentID, structure, levelIndex+1)
+}
+
+func broadcastClock(ctx workflow.Context, state poker.ClockState, remaining time.Duration, eventType string) {
+	state.RemainingSeconds = int(remaining.Seconds())
+	event := poker.TableEvent{Type: eventType, Data: state}
+	err := workflow.ExecuteActivity(ctx, PublishEventActivity, poker.ClockSubject(state.TournamentID), event).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to broadcast clock", "TournamentID", state.TournamentID, "Error", err)
+	}
+}
+
+// applyBlindLevel sets the blinds of the tournament level in play before a
+// hand starts. During a break it waits for the break to end. If the clock
+// cannot be read the table keeps its current blinds.
+func applyBlindLevel(ctx workflow.Context, table *poker.Table) error {
+	for {
+		var state poker.ClockState
+		err := workflow.ExecuteActivity(ctx, CurrentBlindLevelActivity, table.TournamentID).Get(ctx, &state)
+		if err != nil {
+			workflow.GetLogger(ctx).Warn("Failed to read tournament clock", "TableID", table.ID, "TournamentID", table.TournamentID, "Error", err)
+			return nil
+		}
+		if !state.Level.Break {
+			table.ApplyBlindLevel(state.LevelIndex, state.Level)
+			return nil
+		}
+		if err := workflow.Sleep(ctx, time.Duration(max(state.RemainingSeconds, 1))*time.Second); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package temporal
+
+import (
+	"server/internal/poker"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestTournamentClockPause(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterActivity(PublishEventActivity)
+	env.OnActivity(PublishEventActivity, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	structure := poker.BlindStructure{Levels: []poker.BlindLevel{
+		{SmallBlind: 10, BigBlind: 20, Minutes: 10},
+		{SmallBlind: 20, BigBlind: 40, Minutes: 10},
+	}}
+	clockState := func() poker.ClockState {
+		value, err := env.QueryWorkflow(ClockStateQuery)
+		assert.NoError(t, err)
+		var state poker.ClockState
+		assert.NoError(t, value.Get(&state))
+		return state
+	}
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(ControlSignal, ControlRequest{Action: ControlPause})
+	}, 4*time.Minute)
+	env.RegisterDelayedCallback(func() {
+		state := clockState()
+		assert.True(t, state.Paused)
+		assert.Equal(t, 6*60, state.RemainingSeconds, "Paused clock should not run")
+		env.SignalWorkflow(ControlSignal, ControlRequest{Action: ControlResume})
+	}, time.Hour)
+	env.RegisterDelayedCallback(func() {
+		state := clockState()
+		assert.False(t, state.Paused)
+		assert.Equal(t, 5*60, state.RemainingSeconds)
+	}, time.Hour+time.Minute)
+
+	env.ExecuteWorkflow(TournamentClockWorkflow, "7", structure, 0)
+
+	assert.True(t, env.IsWorkflowCompleted())
+	err := env.GetWorkflowError()
+	assert.True(t, workflow.IsContinueAsNewError(err), "Clock should move on to the next level, got %v", err)
+}
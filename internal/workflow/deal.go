@@ -0,0 +1,170 @@
+package temporal
+
+import (
+	"server/internal/poker"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	ProposeDealSignal = "ProposeDeal"
+	DealReplySignal   = "DealReply"
+
+	// dealConfirmTimeout is how long players have to accept a deal.
+	dealConfirmTimeout = 2 * time.Minute
+)
+
+// ProposeDealRequest asks a tournament to stop between hands and offer a deal
+// to the players left. Remainder is left to play for.
+type ProposeDealRequest struct {
+	Method    string
+	Remainder int
+}
+
+// DealReply is a player's answer to a proposed deal, forwarded by the bridge
+// from poker.DealReplySubject.
+type DealReply struct {
+	PlayerID string
+	Accept   bool
+}
+
+type DealVotes struct {
+	Accepted []string
+	Rejected []string
+}
+
+// DealWorkflowID is reused by every deal of a tournament; only one can be
+// open at a time.
+func DealWorkflowID(tournamentID string) string {
+	return "deal-" + tournamentID
+}
+
+// DealWorkflow offers a deal to its players and reports whether every one of
+// them accepted it in time.
+func DealWorkflow(ctx workflow.Context, tournamentID string, deal poker.Deal) (bool, error) {
+	ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+	publish := func(eventType string, data interface{}) {
+		event := poker.TableEvent{Type: eventType, Data: data}
+		err := workflow.ExecuteActivity(ctx, PublishEventActivity, poker.TournamentSubject(tournamentID), event).Get(ctx, nil)
+		if err != nil {
+			workflow.GetLogger(ctx).Warn("Failed to publish deal event", "TournamentID", tournamentID, "Event", eventType, "Error", err)
+		}
+	}
+
+	publish("dealProposed", deal)
+
+	votes := awaitDealReplies(ctx, deal)
+
+	accepted := len(votes.Accepted) == len(deal.Shares)
+	if accepted {
+		publish("dealAccepted", deal)
+	} else {
+		publish("dealRejected", votes)
+	}
+	return accepted, nil
+}
+
+// awaitDealReplies collects the replies of the players in deal until all of
+// them accepted, one rejected or dealConfirmTimeout passed.
+func awaitDealReplies(ctx workflow.Context, deal poker.Deal) DealVotes {
+	var votes DealVotes
+	replyCh := workflow.GetSignalChannel(ctx, DealReplySignal)
+	timerCtx, cancelTimer := workflow.WithCancel(ctx)
+	defer cancelTimer()
+	timer := workflow.NewTimer(timerCtx, dealConfirmTimeout)
+
+	answered := make(map[string]bool)
+	timedOut := false
+	for !timedOut && len(votes.Rejected) == 0 && len(votes.Accepted) < len(deal.Shares) {
+		selector := workflow.NewSelector(ctx)
+		selector.AddReceive(replyCh, func(c workflow.ReceiveChannel, more bool) {
+			var reply DealReply
+			c.Receive(ctx, &reply)
+			if _, ok := deal.AmountFor(reply.PlayerID); !ok || answered[reply.PlayerID] {
+				return
+			}
+			answered[reply.PlayerID] = true
+			if reply.Accept {
+				votes.Accepted = append(votes.Accepted, reply.PlayerID)
+			} else {
+				votes.Rejected = append(votes.Rejected, reply.PlayerID)
+			}
+		})
+		selector.AddFuture(timer, func(f workflow.Future) {
+			timedOut = true
+		})
+		selector.Select(ctx)
+	}
+	return votes
+}
+
+// proposeDeal remembers a deal request. The deal is offered once every table
+// has finished its hand; until then no table deals again.
+func (t *tournamentRun) proposeDeal(ctx workflow.Context, req ProposeDealRequest) {
+	if t.state.DealPending != nil || t.state.Deal != nil {
+		workflow.GetLogger(ctx).Warn("Deal already proposed", "TournamentID", t.tournament.ID)
+		return
+	}
+	t.state.DealPending = &req
+	if t.allIdle() {
+		t.offerDeal(ctx)
+		t.resumeIdleTables(ctx)
+	}
+}
+
+// offerDeal runs a DealWorkflow over the stacks left and the prizes still to
+// be won. An accepted deal replaces the payouts of its players.
+func (t *tournamentRun) offerDeal(ctx workflow.Context) {
+	req := *t.state.DealPending
+	t.state.DealPending = nil
+
+	var players []poker.Player
+	for _, table := range t.state.Tables {
+		for _, player := range table.Table.SeatedPlayers() {
+			players = append(players, *player)
+		}
+	}
+	prizes := t.state.PrizePool.Prizes
+	if len(prizes) > len(players) {
+		prizes = prizes[:len(players)]
+	}
+
+	deal, err := poker.ProposeDeal(req.Method, players, prizes, req.Remainder)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Deal cannot be proposed", "TournamentID", t.tournament.ID, "Error", err)
+		event := poker.TableEvent{Type: "dealRejected", Message: err.Error()}
+		_ = workflow.ExecuteActivity(ctx, PublishEventActivity, poker.TournamentSubject(tournamentKey(t.tournament)), event).Get(ctx, nil)
+		return
+	}
+
+	dealCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:        DealWorkflowID(tournamentKey(t.tournament)),
+		ParentClosePolicy: enums.PARENT_CLOSE_POLICY_TERMINATE,
+	})
+	var accepted bool
+	if err := workflow.ExecuteChildWorkflow(dealCtx, DealWorkflow, tournamentKey(t.tournament), deal).Get(ctx, &accepted); err != nil {
+		workflow.GetLogger(ctx).Error("Deal workflow failed", "TournamentID", t.tournament.ID, "Error", err)
+		return
+	}
+	if accepted {
+		t.state.Deal = &deal
+	}
+}
+
+// prize returns what a finishing position pays a player, taking an accepted
+// deal into account.
+func (t *tournamentRun) prize(player poker.Player, position int, prize int) int {
+	if t.state.Deal == nil {
+		return prize
+	}
+	amount, ok := t.state.Deal.AmountFor(player.ID)
+	if !ok {
+		return prize
+	}
+	if position == 1 {
+		amount += t.state.Deal.Remainder
+	}
+	return amount
+}
@@ -0,0 +1,328 @@
+package temporal
+
+import (
+	"fmt"
+	"server/internal/db/models"
+	"server/internal/poker"
+	"strconv"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	TournamentRegisterSignal = "TournamentRegister"
+	RebuySignal              = "Rebuy"
+	AddOnSignal              = "AddOn"
+)
+
+// TournamentEntryRequest asks a running tournament for a late entry or
+// re-entry, a rebuy or an add-on.
+type TournamentEntryRequest struct {
+	WalletID uint
+}
+
+// entry returns the latest entry of a wallet, or nil.
+func (t *tournamentRun) entry(walletID uint) *poker.TournamentEntry {
+	for i := len(t.state.Entries) - 1; i >= 0; i-- {
+		if t.state.Entries[i].WalletID == walletID {
+			return &t.state.Entries[i]
+		}
+	}
+	return nil
+}
+
+// findPlayer returns a player seated on any table, and that table.
+func (t *tournamentRun) findPlayer(playerID string) (*poker.Player, *TournamentTable) {
+	for i := range t.state.Tables {
+		if player := t.state.Tables[i].Table.FindPlayer(playerID); player != nil {
+			return player, &t.state.Tables[i]
+		}
+	}
+	return nil, nil
+}
+
+func (t *tournamentRun) currentLevel(ctx workflow.Context) (poker.ClockState, error) {
+	var state poker.ClockState
+	err := workflow.ExecuteActivity(ctx, CurrentBlindLevelActivity, tournamentKey(t.tournament)).Get(ctx, &state)
+	return state, err
+}
+
+// register takes a late registration, or a re-entry from a busted player.
+func (t *tournamentRun) register(ctx workflow.Context, req TournamentEntryRequest) {
+	cfg := t.tournament.Configuration
+	playerID := strconv.FormatUint(uint64(req.WalletID), 10)
+
+	if t.state.RegistrationClosed {
+		t.rejectEntry(ctx, playerID, "registrationRejected", fmt.Errorf("registration is closed"))
+		return
+	}
+	previous := t.entry(req.WalletID)
+	if previous != nil && !previous.Busted {
+		t.rejectEntry(ctx, playerID, "registrationRejected", fmt.Errorf("wallet %d is still playing", req.WalletID))
+		return
+	}
+	entries := 0
+	if previous != nil {
+		entries = previous.Entry
+	}
+	if t.tournament.MaxPlayers > 0 && t.remainingPlayers() >= t.tournament.MaxPlayers {
+		t.rejectEntry(ctx, playerID, "registrationRejected", fmt.Errorf("tournament is full"))
+		return
+	}
+	clock, err := t.currentLevel(ctx)
+	if err == nil {
+		err = cfg.CheckLateEntry(clock.LevelIndex, entries)
+	}
+	if err == nil {
+		err = workflow.ExecuteActivity(ctx, BuyInActivity, req.WalletID, cfg.BuyIn).Get(ctx, nil)
+	}
+	if err != nil {
+		t.rejectEntry(ctx, playerID, "registrationRejected", err)
+		return
+	}
+
+	entry := poker.TournamentEntry{WalletID: req.WalletID, Entry: entries + 1, Chips: cfg.StartingChips}
+	t.state.Entries = append(t.state.Entries, entry)
+	t.entryChanged(ctx, entry)
+	t.seatEntry(ctx, poker.Player{ID: playerID, WalletID: req.WalletID, Entry: entry.Entry, Chips: cfg.StartingChips, Bounty: cfg.Bounty})
+}
+
+// rebuy adds chips to a short or busted player during the rebuy period. A
+// busted player rebuys into the same entry and is seated again.
+func (t *tournamentRun) rebuy(ctx workflow.Context, req TournamentEntryRequest) {
+	cfg := t.tournament.Configuration
+	playerID := strconv.FormatUint(uint64(req.WalletID), 10)
+
+	entry := t.entry(req.WalletID)
+	if entry == nil {
+		t.rejectEntry(ctx, playerID, "rebuyRejected", fmt.Errorf("wallet %d is not registered", req.WalletID))
+		return
+	}
+	player, table := t.findPlayer(playerID)
+	chips := 0
+	if player != nil {
+		chips = player.Chips
+	}
+	clock, err := t.currentLevel(ctx)
+	if err == nil {
+		err = cfg.CheckRebuy(clock.LevelIndex, chips, *entry)
+	}
+	if err == nil {
+		err = workflow.ExecuteActivity(ctx, BuyInActivity, req.WalletID, cfg.RebuyCost).Get(ctx, nil)
+	}
+	if err != nil {
+		t.rejectEntry(ctx, playerID, "rebuyRejected", err)
+		return
+	}
+
+	entry.Rebuys++
+	entry.Chips += cfg.RebuyChips
+	t.entryChanged(ctx, *entry)
+
+	if player != nil {
+		table.addChips(playerID, cfg.RebuyChips, cfg.Bounty)
+		return
+	}
+	entry.Busted = false
+	t.removeElimination(ctx, req.WalletID, entry.Entry)
+	t.seatEntry(ctx, poker.Player{ID: playerID, WalletID: req.WalletID, Entry: entry.Entry, Chips: cfg.RebuyChips, Bounty: cfg.Bounty})
+}
+
+// addOn sells the one-time add-on to a seated player during a break.
+func (t *tournamentRun) addOn(ctx workflow.Context, req TournamentEntryRequest) {
+	cfg := t.tournament.Configuration
+	playerID := strconv.FormatUint(uint64(req.WalletID), 10)
+
+	entry := t.entry(req.WalletID)
+	player, table := t.findPlayer(playerID)
+	if entry == nil || player == nil {
+		t.rejectEntry(ctx, playerID, "addOnRejected", fmt.Errorf("wallet %d is not playing", req.WalletID))
+		return
+	}
+	clock, err := t.currentLevel(ctx)
+	if err == nil {
+		err = cfg.CheckAddOn(clock.Level, *entry)
+	}
+	if err == nil {
+		err = workflow.ExecuteActivity(ctx, BuyInActivity, req.WalletID, cfg.AddOnCost).Get(ctx, nil)
+	}
+	if err != nil {
+		t.rejectEntry(ctx, playerID, "addOnRejected", err)
+		return
+	}
+
+	entry.AddOn = true
+	entry.Chips += cfg.AddOnChips
+	t.entryChanged(ctx, *entry)
+	table.addChips(playerID, cfg.AddOnChips, 0)
+}
+
+// addChips credits bought chips, and the bounty a rebuy buys, on the
+// tournament's copy of the table and queues them for the table itself.
+func (table *TournamentTable) addChips(playerID string, chips int, bounty int) {
+	player := table.Table.FindPlayer(playerID)
+	player.Chips += chips
+	player.Bounty += bounty
+	for i := range table.Arrivals {
+		if table.Arrivals[i].ID == playerID {
+			table.Arrivals[i].Chips += chips
+			table.Arrivals[i].Bounty += bounty
+			return
+		}
+	}
+	table.TopUps = append(table.TopUps, ChipTopUp{PlayerID: playerID, Chips: chips, Bounty: bounty})
+}
+
+// keepToppedUp seats again a player who rebought or bought the add-on during
+// the hand they busted in, with the chips and bounty they bought. The table
+// stood them up, so they go back to it as an arrival.
+func (t *tournamentRun) keepToppedUp(ctx workflow.Context, table *TournamentTable, player poker.Player) bool {
+	chips, bounty := 0, 0
+	for _, topUp := range table.TopUps {
+		if topUp.PlayerID == player.ID {
+			chips += topUp.Chips
+			bounty += topUp.Bounty
+		}
+	}
+	if chips == 0 {
+		return false
+	}
+
+	seated := poker.Player{
+		ID:          player.ID,
+		WalletID:    player.WalletID,
+		Entry:       player.Entry,
+		Chips:       chips,
+		Bounty:      bounty,
+		BountiesWon: player.BountiesWon,
+	}
+	if err := table.Table.SitPlayer(player.Seat, seated); err != nil {
+		reason := fmt.Sprintf("player %s busted after buying %d chips that could not be seated: %v", player.ID, chips, err)
+		workflow.GetLogger(ctx).Error("Failed to keep topped up player", "TournamentID", t.tournament.ID, "PlayerID", player.ID, "Error", err)
+		alertAdmins(ctx, table.Table, reason)
+		return false
+	}
+
+	topUps := table.TopUps[:0]
+	for _, topUp := range table.TopUps {
+		if topUp.PlayerID != player.ID {
+			topUps = append(topUps, topUp)
+		}
+	}
+	table.TopUps = topUps
+	table.Arrivals = append(table.Arrivals, *table.Table.FindPlayer(player.ID))
+	return true
+}
+
+func (t *tournamentRun) removeElimination(ctx workflow.Context, walletID uint, entry int) {
+	for i, player := range t.state.RoundEliminated {
+		if player.WalletID == walletID && player.Entry == entry {
+			t.state.RoundEliminated = append(t.state.RoundEliminated[:i], t.state.RoundEliminated[i+1:]...)
+			return
+		}
+	}
+	for i := len(t.state.Eliminated) - 1; i >= 0; i-- {
+		player := t.state.Eliminated[i].Player
+		if player.WalletID == walletID && player.Entry == entry {
+			t.state.Eliminated = append(t.state.Eliminated[:i], t.state.Eliminated[i+1:]...)
+			err := workflow.ExecuteActivity(ctx, RecordEliminationsActivity, t.tournament.ID, []models.Ranking{{
+				TournamentID: t.tournament.ID, WalletID: walletID, Entry: entry,
+			}}).Get(ctx, nil)
+			if err != nil {
+				workflow.GetLogger(ctx).Error("Failed to clear elimination", "TournamentID", t.tournament.ID, "WalletID", walletID, "Error", err)
+			}
+			return
+		}
+	}
+}
+
+// seatEntry seats a new or returning player on the table with the fewest
+// players, opening a new table when every seat is taken.
+func (t *tournamentRun) seatEntry(ctx workflow.Context, player poker.Player) {
+	var target *TournamentTable
+	for i := range t.state.Tables {
+		table := &t.state.Tables[i]
+		if len(table.Table.OpenSeats()) == 0 {
+			continue
+		}
+		if target == nil || table.Table.PlayerCount() < target.Table.PlayerCount() {
+			target = table
+		}
+	}
+
+	if target == nil {
+		table, err := t.openTable(ctx)
+		if err != nil {
+			workflow.GetLogger(ctx).Error("Failed to open tournament table", "TournamentID", t.tournament.ID, "Error", err)
+			return
+		}
+		target = table
+	}
+
+	seat, err := target.Table.SitPlayerAnywhere(player)
+	if err != nil {
+		workflow.GetLogger(ctx).Error("Failed to seat entry", "TableID", target.Table.ID, "PlayerID", player.ID, "Error", err)
+		return
+	}
+	player.Seat = seat
+	target.Arrivals = append(target.Arrivals, player)
+
+	if err := workflow.ExecuteActivity(ctx, SaveSeatingActivity, t.tournament.ID, []poker.Table{target.Table}).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Error("Failed to save seating", "TableID", target.Table.ID, "Error", err)
+	}
+	t.notifyPlayer(ctx, player.ID, poker.TableEvent{
+		Type: "tournamentSeat",
+		Data: map[string]interface{}{"tableId": target.Table.ID, "seat": seat, "chips": player.Chips},
+	})
+
+	t.balance(ctx)
+	t.resumeIdleTables(ctx)
+}
+
+// openTable starts an empty table that waits for players.
+func (t *tournamentRun) openTable(ctx workflow.Context) (*TournamentTable, error) {
+	number := t.state.TableCount + 1
+	table, err := poker.NewTable(poker.TournamentTableID(tournamentKey(t.tournament), number), t.tournament.TableSize)
+	if err != nil {
+		return nil, err
+	}
+	table.GameType = poker.GameTournament
+	table.TournamentID = tournamentKey(t.tournament)
+	table.Number = number
+	table.TurnTime = t.tournament.TurnSeconds
+	table.ProgressiveBounty = t.tournament.Configuration.ProgressiveBounty
+
+	if err := startTournamentTable(ctx, t.tournament, table, nil, t.config); err != nil {
+		return nil, err
+	}
+	t.state.TableCount = number
+	t.state.Tables = append(t.state.Tables, TournamentTable{Table: table, Idle: true})
+	return &t.state.Tables[len(t.state.Tables)-1], nil
+}
+
+// entryChanged stores an entry and recomputes the prize pool.
+func (t *tournamentRun) entryChanged(ctx workflow.Context, entry poker.TournamentEntry) {
+	if err := workflow.ExecuteActivity(ctx, SaveEntryActivity, t.tournament.ID, entry).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Error("Failed to save tournament entry", "TournamentID", t.tournament.ID, "WalletID", entry.WalletID, "Error", err)
+	}
+	pool, err := prizePool(t.tournament.Configuration, t.state.Entries)
+	if err != nil {
+		workflow.GetLogger(ctx).Error("Failed to update prize pool", "TournamentID", t.tournament.ID, "Error", err)
+		return
+	}
+	t.state.PrizePool = pool
+	t.notifyPlayer(ctx, strconv.FormatUint(uint64(entry.WalletID), 10), poker.TableEvent{Type: "tournamentEntry", Data: entry})
+}
+
+func (t *tournamentRun) rejectEntry(ctx workflow.Context, playerID string, eventType string, err error) {
+	workflow.GetLogger(ctx).Warn("Tournament entry rejected", "TournamentID", t.tournament.ID, "PlayerID", playerID, "Error", err)
+	t.notifyPlayer(ctx, playerID, poker.TableEvent{Type: eventType, Message: err.Error()})
+}
+
+func (t *tournamentRun) notifyPlayer(ctx workflow.Context, playerID string, event poker.TableEvent) {
+	event.PlayerID = playerID
+	err := workflow.ExecuteActivity(ctx, PublishEventActivity, poker.PlayerSubject(tournamentKey(t.tournament), playerID), event).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to notify tournament player", "TournamentID", t.tournament.ID, "PlayerID", playerID, "Error", err)
+	}
+}
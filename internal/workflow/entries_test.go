@@ -0,0 +1,140 @@
+package temporal
+
+import (
+	"server/internal/db/models"
+	"server/internal/poker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+// rebuyWorkflow runs a single rebuy against a tournament in progress.
+func rebuyWorkflow(ctx workflow.Context, tournament models.Tournament, state TournamentState, req TournamentEntryRequest) (TournamentState, error) {
+	ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+	t := &tournamentRun{tournament: tournament, state: state}
+	t.rebuy(ctx, req)
+	return t.state, nil
+}
+
+func TestRebuyRestoresBounty(t *testing.T) {
+	cfg := poker.TournamentConfig{
+		BuyIn: 100, Bounty: 50, StartingChips: 1000,
+		RebuyCost: 100, RebuyChips: 1000, RebuyLevels: 2,
+		Payouts: poker.PayoutStructure{Tiers: []poker.PayoutTier{{Percentages: []int{10000}}}},
+	}
+	tournament := models.Tournament{ID: 7, TableSize: 6, Configuration: cfg}
+
+	// Player 1 knocked out player 2 and collected their bounty.
+	table, err := poker.NewTable(poker.TournamentTableID("7", 1), 6)
+	require.NoError(t, err)
+	require.NoError(t, table.SitPlayer(1, poker.Player{ID: "1", WalletID: 1, Entry: 1, Chips: 2000, Bounty: 50, BountiesWon: 50}))
+	state := TournamentState{
+		Entries:    []poker.TournamentEntry{{WalletID: 1, Entry: 1}, {WalletID: 2, Entry: 1, Busted: true}},
+		Tables:     []TournamentTable{{Table: table}},
+		TableCount: 1,
+		Eliminated: []poker.Finish{{Player: poker.Player{ID: "2", WalletID: 2, Entry: 1}, Position: 2, Places: 1}},
+	}
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(rebuyWorkflow)
+	for _, activity := range []interface{}{CurrentBlindLevelActivity, BuyInActivity, SaveEntryActivity, SaveSeatingActivity, PublishEventActivity} {
+		env.RegisterActivity(activity)
+	}
+	env.OnActivity(CurrentBlindLevelActivity, mock.Anything, mock.Anything).Return(poker.ClockState{}, nil)
+	env.OnActivity(BuyInActivity, mock.Anything, uint(2), 100).Return(nil).Once()
+	env.OnActivity(SaveEntryActivity, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(SaveSeatingActivity, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(PublishEventActivity, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	env.ExecuteWorkflow(rebuyWorkflow, tournament, state, TournamentEntryRequest{WalletID: 2})
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	require.NoError(t, env.GetWorkflowResult(&state))
+
+	bounties := 0
+	var rebought *poker.Player
+	for _, table := range state.Tables {
+		for _, player := range table.Table.SeatedPlayers() {
+			bounties += player.Bounty + player.BountiesWon
+			if player.ID == "2" {
+				rebought = player
+			}
+		}
+	}
+	require.NotNil(t, rebought, "Busted player should be seated again")
+	assert.Equal(t, cfg.Bounty, rebought.Bounty, "Rebuy should restore the starting bounty")
+	assert.Equal(t, state.PrizePool.Bounties, bounties, "Bounties should add up to the bounty pool")
+	assert.Equal(t, 150, bounties)
+}
+
+// handCompletedWorkflow reports one hand result to a tournament in progress.
+func handCompletedWorkflow(ctx workflow.Context, tournament models.Tournament, state TournamentState, result HandResult) (TournamentState, error) {
+	ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+	t := &tournamentRun{tournament: tournament, state: state}
+	t.handCompleted(ctx, result)
+	return t.state, nil
+}
+
+func TestRebuyDuringLostHandKeepsPlayer(t *testing.T) {
+	cfg := poker.TournamentConfig{BuyIn: 100, Bounty: 50, StartingChips: 1000, RebuyCost: 100, RebuyChips: 1000, RebuyLevels: 2}
+	tournament := models.Tournament{ID: 7, TableSize: 6, Configuration: cfg}
+	tableID := poker.TournamentTableID("7", 1)
+
+	// Player 2 rebought while the hand was dealt, then lost it to player 1.
+	table, err := poker.NewTable(tableID, 6)
+	require.NoError(t, err)
+	require.NoError(t, table.SitPlayer(1, poker.Player{ID: "1", WalletID: 1, Entry: 1, Chips: 1000, Bounty: 50}))
+	require.NoError(t, table.SitPlayer(2, poker.Player{ID: "2", WalletID: 2, Entry: 1, Chips: 2000, Bounty: 100}))
+	require.NoError(t, table.SitPlayer(3, poker.Player{ID: "3", WalletID: 3, Entry: 1, Chips: 1000, Bounty: 50}))
+	state := TournamentState{
+		Entries:    []poker.TournamentEntry{{WalletID: 1, Entry: 1}, {WalletID: 2, Entry: 1, Rebuys: 1}, {WalletID: 3, Entry: 1}},
+		Tables:     []TournamentTable{{Table: table, TopUps: []ChipTopUp{{PlayerID: "2", Chips: 1000, Bounty: 50}}}},
+		TableCount: 1,
+	}
+
+	reported, err := poker.NewTable(tableID, 6)
+	require.NoError(t, err)
+	require.NoError(t, reported.SitPlayer(1, poker.Player{ID: "1", WalletID: 1, Entry: 1, Chips: 2000, Bounty: 50, BountiesWon: 50}))
+	require.NoError(t, reported.SitPlayer(3, poker.Player{ID: "3", WalletID: 3, Entry: 1, Chips: 1000, Bounty: 50}))
+	busted := poker.Player{ID: "2", WalletID: 2, Entry: 1, Seat: 2, IsEliminated: true}
+	result := HandResult{Table: reported, Eliminated: []poker.Player{busted}}
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(handCompletedWorkflow)
+	for _, activity := range []interface{}{RecordEliminationsActivity, PublishEventActivity} {
+		env.RegisterActivity(activity)
+	}
+	env.OnActivity(RecordEliminationsActivity, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(PublishEventActivity, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	var next NextHandRequest
+	env.OnSignalExternalWorkflow(mock.Anything, TableWorkflowID(tableID), mock.Anything, NextHandSignal, mock.Anything).Return(
+		func(_ string, _ string, _ string, _ string, arg interface{}) error {
+			next = arg.(NextHandRequest)
+			return nil
+		})
+
+	env.ExecuteWorkflow(handCompletedWorkflow, tournament, state, result)
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	require.NoError(t, env.GetWorkflowResult(&state))
+
+	player := state.Tables[0].Table.FindPlayer("2")
+	require.NotNil(t, player, "Player who rebought should keep the seat")
+	assert.Equal(t, 2, player.Seat)
+	assert.Equal(t, 1000, player.Chips)
+	assert.Equal(t, 50, player.Bounty)
+	assert.False(t, state.Entries[1].Busted)
+	assert.Empty(t, state.Eliminated)
+	assert.Empty(t, state.RoundEliminated)
+
+	require.Len(t, next.Arrivals, 1, "The table should seat the player again")
+	assert.Equal(t, "2", next.Arrivals[0].ID)
+	assert.Equal(t, 1000, next.Arrivals[0].Chips)
+	assert.Empty(t, next.TopUps)
+}
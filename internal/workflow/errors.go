@@ -0,0 +1,63 @@
+package temporal
+
+import (
+	"encoding/json"
+	"errors"
+	"server/internal/db"
+
+	"go.temporal.io/api/serviceerror"
+	sdktemporal "go.temporal.io/sdk/temporal"
+	"gorm.io/gorm"
+)
+
+// Types of the application errors returned by activities. NATS, database and
+// Temporal outages are retried by the activity's retry policy; the rest fail
+// at once because retrying cannot fix them.
+const (
+	NATSUnavailableError     = "NATSUnavailable"
+	DatabaseUnavailableError = "DatabaseUnavailable"
+	TemporalUnavailableError = "TemporalUnavailable"
+	InvalidPayloadError      = "InvalidPayload"
+	NotFoundError            = "NotFound"
+	InsufficientBalanceError = "InsufficientBalance"
+)
+
+// publishError classifies a failure to publish to NATS.
+func publishError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var unsupportedType *json.UnsupportedTypeError
+	var unsupportedValue *json.UnsupportedValueError
+	var marshaler *json.MarshalerError
+	if errors.As(err, &unsupportedType) || errors.As(err, &unsupportedValue) || errors.As(err, &marshaler) {
+		return sdktemporal.NewNonRetryableApplicationError(err.Error(), InvalidPayloadError, err)
+	}
+	return sdktemporal.NewApplicationErrorWithCause(err.Error(), NATSUnavailableError, err)
+}
+
+// dbError classifies a failure to read or write the database.
+func dbError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, db.ErrInsufficientBalance):
+		return sdktemporal.NewNonRetryableApplicationError(err.Error(), InsufficientBalanceError, err)
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, db.ErrWalletNotFound):
+		return sdktemporal.NewNonRetryableApplicationError(err.Error(), NotFoundError, err)
+	}
+	return sdktemporal.NewApplicationErrorWithCause(err.Error(), DatabaseUnavailableError, err)
+}
+
+// queryError classifies a failure to query a workflow. A workflow that does
+// not exist will not appear by retrying.
+func queryError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var notFound *serviceerror.NotFound
+	if errors.As(err, &notFound) {
+		return sdktemporal.NewNonRetryableApplicationError(err.Error(), NotFoundError, err)
+	}
+	return sdktemporal.NewApplicationErrorWithCause(err.Error(), TemporalUnavailableError, err)
+}
@@ -0,0 +1,59 @@
+package temporal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"server/config"
+	"server/internal/db"
+	"server/internal/poker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.temporal.io/api/serviceerror"
+	sdktemporal "go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+)
+
+func TestErrorClassification(t *testing.T) {
+	_, marshalErr := json.Marshal(math.Inf(1))
+	tests := []struct {
+		err          error
+		errType      string
+		nonRetryable bool
+	}{
+		{publishError(errors.New("nats: timeout")), NATSUnavailableError, false},
+		{publishError(fmt.Errorf("failed to marshal: %w", marshalErr)), InvalidPayloadError, true},
+		{dbError(errors.New("connection refused")), DatabaseUnavailableError, false},
+		{dbError(fmt.Errorf("buy-in: %w", db.ErrInsufficientBalance)), InsufficientBalanceError, true},
+		{dbError(fmt.Errorf("credit: %w", db.ErrWalletNotFound)), NotFoundError, true},
+		{queryError(fmt.Errorf("query clock: %w", serviceerror.NewNotFound("workflow not found"))), NotFoundError, true},
+		{queryError(fmt.Errorf("query clock: %w", serviceerror.NewUnavailable("connection refused"))), TemporalUnavailableError, false},
+	}
+	for _, test := range tests {
+		var appErr *sdktemporal.ApplicationError
+		if assert.ErrorAs(t, test.err, &appErr) {
+			assert.Equal(t, test.errType, appErr.Type())
+			assert.Equal(t, test.nonRetryable, appErr.NonRetryable(), test.errType)
+		}
+	}
+	assert.NoError(t, publishError(nil))
+	assert.NoError(t, dbError(nil))
+	assert.NoError(t, queryError(nil))
+}
+
+func TestDealCardsRejectsMissingDeck(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(DealCardsActivity)
+
+	table, err := poker.NewTable("1", 6)
+	assert.NoError(t, err)
+	_, err = env.ExecuteActivity(DealCardsActivity, &table, []poker.Card(nil), &config.Config{})
+	var appErr *sdktemporal.ApplicationError
+	if assert.ErrorAs(t, err, &appErr) {
+		assert.Equal(t, InvalidPayloadError, appErr.Type())
+		assert.True(t, appErr.NonRetryable())
+	}
+}
@@ -0,0 +1,88 @@
+package temporal
+
+import (
+	"context"
+	"server/config"
+	"server/internal/poker"
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// legacyHandleTurns is the activity the first TableWorkflow ran every betting
+// round in. The first workers never registered it, so the hands they started
+// are stuck retrying it.
+const legacyHandleTurns = "HandleTurns"
+
+// legacyVoidReason is why hands of the first TableWorkflow are voided.
+const legacyVoidReason = "hand started before betting moved to the table workflow"
+
+// LegacyHandleTurnsActivity completes the HandleTurns tasks left by the first
+// TableWorkflow without playing the betting round, so their hands reach
+// legacyTableWorkflow and are voided.
+func LegacyHandleTurnsActivity(ctx context.Context, table *poker.Table) (*poker.Table, error) {
+	return table, nil
+}
+
+// legacyTableWorkflow replays hands started by the first TableWorkflow. It
+// issues the commands that revision recorded for as long as it replays them,
+// then voids the hand, which gives every player their bets back, alerts the
+// staff and ends the run instead of playing on.
+//
+// A DealCardsActivity scheduled by that revision and not yet run carries its
+// old arguments, fails to decode and is retried until the run is terminated.
+func legacyTableWorkflow(ctx workflow.Context, table poker.Table, config *config.Config) (poker.Table, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute * 5,
+	})
+
+	// replay runs a recorded activity and reports false once the history has
+	// no more of them.
+	replay := func(result interface{}, activity string, args ...interface{}) (bool, error) {
+		if !workflow.IsReplaying(ctx) {
+			return false, nil
+		}
+		return true, workflow.ExecuteActivity(ctx, activity, args...).Get(ctx, result)
+	}
+
+	ok, err := replay(&table, "DealPreFlop", &table, config)
+	if !ok || err != nil {
+		return endLegacyHand(ctx, table, err)
+	}
+	if table.PlayerCount() < 2 {
+		return table, nil
+	}
+	var cards poker.Table
+	if ok, err := replay(&cards, "DealCardsActivity", &table, config); !ok || err != nil {
+		return endLegacyHand(ctx, table, err)
+	}
+
+	for _, deal := range []string{"", "DealFlop", "DealTurn", "DealRiver"} {
+		if deal != "" {
+			if ok, err := replay(&table, deal, &table, config); !ok || err != nil {
+				return endLegacyHand(ctx, table, err)
+			}
+		}
+		if ok, err := replay(&table, legacyHandleTurns, &table); !ok || err != nil {
+			return endLegacyHand(ctx, table, err)
+		}
+		if table.AllFoldExceptOne {
+			_, err := replay(&table, "ShowDownAllFoldExecptOne", &table)
+			return table, err
+		}
+	}
+
+	_, err = replay(&table, "ShowDown", &table)
+	return table, err
+}
+
+// endLegacyHand voids a hand of the first TableWorkflow that got past its
+// recorded history. A recorded failure ends the run as it did back then.
+func endLegacyHand(ctx workflow.Context, table poker.Table, err error) (poker.Table, error) {
+	if err != nil {
+		return table, err
+	}
+	table, err = voidHand(ctx, table, legacyVoidReason)
+	alertAdmins(ctx, table, legacyVoidReason)
+	return table, err
+}
@@ -0,0 +1,49 @@
+package temporal
+
+import (
+	"context"
+	"server/config"
+	"server/internal/poker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestLegacyHandPastItsHistoryIsVoided(t *testing.T) {
+	table, err := poker.NewTable("1", 6)
+	require.NoError(t, err)
+	require.NoError(t, table.SitPlayer(1, poker.Player{ID: "a", Chips: 990, TotalBet: 10}))
+	require.NoError(t, table.SitPlayer(2, poker.Player{ID: "b", Chips: 980, TotalBet: 20}))
+
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(TableWorkflow)
+	env.RegisterActivity(VoidHandActivity)
+	env.RegisterActivity(PublishEventActivity)
+	env.OnGetVersion(workflowTurnsChange, workflow.DefaultVersion, 1).Return(workflow.DefaultVersion)
+	env.OnActivity(VoidHandActivity, mock.Anything, mock.Anything, legacyVoidReason).Return(
+		func(_ context.Context, table *poker.Table, reason string) (*poker.Table, error) {
+			table.VoidHand(reason)
+			return table, nil
+		}).Once()
+	var alerts int
+	env.OnActivity(PublishEventActivity, mock.Anything, poker.AdminSubject(), mock.Anything).Return(
+		func(_ context.Context, _ string, _ poker.TableEvent) error {
+			alerts++
+			return nil
+		})
+
+	env.ExecuteWorkflow(TableWorkflow, table, &config.Config{})
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	require.NoError(t, env.GetWorkflowResult(&table))
+
+	assert.Equal(t, "voided", table.CurrentStage)
+	assert.Equal(t, 1000, table.FindPlayer("a").Chips, "Bets should be given back")
+	assert.Equal(t, 1000, table.FindPlayer("b").Chips, "Bets should be given back")
+	assert.Equal(t, 1, alerts, "Staff should be told about the voided hand")
+}
@@ -0,0 +1,56 @@
+package temporal
+
+import (
+	"time"
+
+	sdktemporal "go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	// stagePause lets players see a new street before the action starts.
+	stagePause = 2 * time.Second
+
+	// activityHeartbeat bounds how long an activity that loops over players
+	// or tables may go without reporting progress.
+	activityHeartbeat = 10 * time.Second
+)
+
+var (
+	// handRetryPolicy retries the steps of a hand for a short while. A step
+	// that keeps failing voids the hand instead of freezing the table.
+	handRetryPolicy = &sdktemporal.RetryPolicy{
+		InitialInterval:    500 * time.Millisecond,
+		BackoffCoefficient: 2,
+		MaximumInterval:    5 * time.Second,
+		MaximumAttempts:    5,
+	}
+
+	// durableRetryPolicy keeps retrying wallet movements, persistence and
+	// notifications until NATS or the database are back. Activities are
+	// idempotent, so a retry never applies anything twice.
+	durableRetryPolicy = &sdktemporal.RetryPolicy{
+		InitialInterval:    time.Second,
+		BackoffCoefficient: 2,
+		MaximumInterval:    time.Minute,
+	}
+)
+
+func handActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy:         handRetryPolicy,
+	}
+}
+
+func durableActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy:         durableRetryPolicy,
+	}
+}
+
+// withHeartbeat is for activities that record heartbeats as they go.
+func withHeartbeat(ctx workflow.Context) workflow.Context {
+	return workflow.WithHeartbeatTimeout(ctx, activityHeartbeat)
+}
@@ -0,0 +1,45 @@
+package temporal
+
+import (
+	"server/internal/poker"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// Queries answered by the workflow that owns a table, TableWorkflowID.
+const (
+	TableViewQuery  = "TableView"
+	PlayerViewQuery = "PlayerView"
+	StageQuery      = "Stage"
+	PotsQuery       = "Pots"
+	HistoryQuery    = "History"
+)
+
+// registerTableQueries answers the table queries from table. dealt is the
+// table the current hand was dealt on, holding the hole cards, or nil between
+// hands. Registering again replaces the previous handlers.
+func registerTableQueries(ctx workflow.Context, table *poker.Table, dealt *poker.Table) error {
+	handlers := map[string]interface{}{
+		TableViewQuery: func() (poker.Table, error) {
+			return table.PublicView(), nil
+		},
+		PlayerViewQuery: func(playerID string) (poker.Table, error) {
+			return table.PrivateView(playerID, dealt)
+		},
+		StageQuery: func() (string, error) {
+			return table.CurrentStage, nil
+		},
+		PotsQuery: func() ([]poker.Pot, error) {
+			return table.PotBreakdown(), nil
+		},
+		HistoryQuery: func() ([]poker.HandAction, error) {
+			return table.History, nil
+		},
+	}
+	for queryType, handler := range handlers {
+		if err := workflow.SetQueryHandler(ctx, queryType, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package temporal
+
+import (
+	"fmt"
+	"server/config"
+	"server/internal/db/models"
+	"server/internal/poker"
+	"strconv"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	CloseRegistrationSignal = "CloseRegistration"
+	StartTournamentSignal   = "StartTournament"
+)
+
+// TournamentRegistrationWorkflow takes registrations for a scheduled
+// tournament under the tournament's workflow ID, from the time its
+// registration opens until it is closed. The tournament starts on a
+// StartTournamentSignal, which carries the tournament as last saved, if
+// MinPlayers have registered; otherwise it is cancelled and every buy-in
+// refunded. Registration left open at the start carries on as late
+// registration in the TournamentWorkflow.
+func TournamentRegistrationWorkflow(ctx workflow.Context, tournament models.Tournament, config *config.Config) error {
+	ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+
+	s := &registration{tournament: tournament}
+	closed, started := false, false
+	var terminateReason string
+
+	selector := workflow.NewSelector(ctx)
+	selector.AddReceive(workflow.GetSignalChannel(ctx, TournamentRegisterSignal), func(c workflow.ReceiveChannel, more bool) {
+		var req TournamentEntryRequest
+		c.Receive(ctx, &req)
+		if closed {
+			playerID := strconv.FormatUint(uint64(req.WalletID), 10)
+			s.notify(ctx, playerID, poker.TableEvent{Type: "registrationRejected", Message: "registration is closed"})
+			return
+		}
+		s.register(ctx, req)
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, CloseRegistrationSignal), func(c workflow.ReceiveChannel, more bool) {
+		c.Receive(ctx, nil)
+		closed = true
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, StartTournamentSignal), func(c workflow.ReceiveChannel, more bool) {
+		c.Receive(ctx, &s.tournament)
+		started = true
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, ControlSignal), func(c workflow.ReceiveChannel, more bool) {
+		var req ControlRequest
+		c.Receive(ctx, &req)
+		if req.Action == ControlTerminate {
+			terminateReason = controlReason(req, "cancelled by admin")
+		}
+	})
+
+	for !started && terminateReason == "" {
+		selector.Select(ctx)
+	}
+
+	if terminateReason != "" {
+		return s.cancel(ctx, terminateReason)
+	}
+	if len(s.registered) < s.tournament.MinPlayers {
+		return s.cancel(ctx, s.shortMessage())
+	}
+	return workflow.NewContinueAsNewError(ctx, TournamentWorkflow, s.tournament, (*TournamentState)(nil), config)
+}
+
+type registration struct {
+	tournament models.Tournament
+	registered []uint
+}
+
+func (s *registration) register(ctx workflow.Context, req TournamentEntryRequest) {
+	playerID := strconv.FormatUint(uint64(req.WalletID), 10)
+	for _, walletID := range s.registered {
+		if walletID == req.WalletID {
+			s.notify(ctx, playerID, poker.TableEvent{Type: "registrationRejected", Message: "already registered"})
+			return
+		}
+	}
+	if s.tournament.MaxPlayers > 0 && len(s.registered) >= s.tournament.MaxPlayers {
+		s.notify(ctx, playerID, poker.TableEvent{Type: "registrationRejected", Message: "tournament is full"})
+		return
+	}
+
+	err := workflow.ExecuteActivity(ctx, BuyInActivity, req.WalletID, s.tournament.Configuration.BuyIn).Get(ctx, nil)
+	if err != nil {
+		s.notify(ctx, playerID, poker.TableEvent{Type: "registrationRejected", Message: err.Error()})
+		return
+	}
+	entry := poker.TournamentEntry{WalletID: req.WalletID, Entry: 1, Chips: s.tournament.Configuration.StartingChips}
+	if err := workflow.ExecuteActivity(ctx, SaveEntryActivity, s.tournament.ID, entry).Get(ctx, nil); err != nil {
+		_ = workflow.ExecuteActivity(ctx, CashOutActivity, req.WalletID, s.tournament.Configuration.BuyIn).Get(ctx, nil)
+		s.notify(ctx, playerID, poker.TableEvent{Type: "registrationRejected", Message: err.Error()})
+		return
+	}
+
+	s.registered = append(s.registered, req.WalletID)
+	s.notify(ctx, playerID, poker.TableEvent{
+		Type: "registered",
+		Data: map[string]int{"registered": len(s.registered), "maxPlayers": s.tournament.MaxPlayers},
+	})
+}
+
+func (s *registration) shortMessage() string {
+	return fmt.Sprintf("only %d of %d required players registered", len(s.registered), s.tournament.MinPlayers)
+}
+
+// cancel refunds every registered player and tells them why.
+func (s *registration) cancel(ctx workflow.Context, message string) error {
+	err := workflow.ExecuteActivity(withHeartbeat(ctx), CancelTournamentActivity, s.tournament.ID, s.registered, s.tournament.Configuration.BuyIn).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, walletID := range s.registered {
+		s.notify(ctx, strconv.FormatUint(uint64(walletID), 10), poker.TableEvent{Type: "tournamentCancelled", Message: message})
+	}
+	return nil
+}
+
+func (s *registration) notify(ctx workflow.Context, playerID string, event poker.TableEvent) {
+	event.PlayerID = playerID
+	err := workflow.ExecuteActivity(ctx, PublishEventActivity, poker.PlayerSubject(tournamentKey(s.tournament), playerID), event).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to notify registered player", "TournamentID", s.tournament.ID, "PlayerID", playerID, "Error", err)
+	}
+}
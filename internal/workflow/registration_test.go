@@ -0,0 +1,89 @@
+package temporal
+
+import (
+	"server/internal/db/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func newRegistrationEnv() *testsuite.TestWorkflowEnvironment {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterActivity(BuyInActivity)
+	env.RegisterActivity(SaveEntryActivity)
+	env.RegisterActivity(PublishEventActivity)
+	env.RegisterActivity(CancelTournamentActivity)
+	env.OnActivity(BuyInActivity, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(SaveEntryActivity, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(PublishEventActivity, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return env
+}
+
+func TestRegistrationClosesAndStartsTournament(t *testing.T) {
+	env := newRegistrationEnv()
+	tournament := models.Tournament{ID: 7, MinPlayers: 2, MaxPlayers: 10}
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(TournamentRegisterSignal, TournamentEntryRequest{WalletID: 1})
+		env.SignalWorkflow(TournamentRegisterSignal, TournamentEntryRequest{WalletID: 2})
+	}, time.Minute)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(CloseRegistrationSignal, nil)
+		env.SignalWorkflow(TournamentRegisterSignal, TournamentEntryRequest{WalletID: 3})
+	}, time.Hour)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(StartTournamentSignal, tournament)
+	}, 2*time.Hour)
+
+	env.ExecuteWorkflow(TournamentRegistrationWorkflow, tournament, nil)
+
+	assert.True(t, env.IsWorkflowCompleted())
+	assert.True(t, workflow.IsContinueAsNewError(env.GetWorkflowError()), "Tournament should start, got %v", env.GetWorkflowError())
+	env.AssertActivityNumberOfCalls(t, "BuyInActivity", 2)
+	env.AssertActivityNumberOfCalls(t, "CancelTournamentActivity", 0)
+}
+
+func TestRegistrationCancelledWithoutEnoughPlayers(t *testing.T) {
+	env := newRegistrationEnv()
+	tournament := models.Tournament{ID: 7, MinPlayers: 2, MaxPlayers: 10}
+	env.OnActivity(CancelTournamentActivity, mock.Anything, tournament.ID, []uint{1}, 0).Return(nil).Once()
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(TournamentRegisterSignal, TournamentEntryRequest{WalletID: 1})
+	}, time.Minute)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(StartTournamentSignal, tournament)
+	}, time.Hour)
+
+	env.ExecuteWorkflow(TournamentRegistrationWorkflow, tournament, nil)
+
+	assert.True(t, env.IsWorkflowCompleted())
+	assert.NoError(t, env.GetWorkflowError())
+	env.AssertExpectations(t)
+}
+
+func TestRegistrationWithoutPlayerCap(t *testing.T) {
+	env := newRegistrationEnv()
+	tournament := models.Tournament{ID: 7, MinPlayers: 2}
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(TournamentRegisterSignal, TournamentEntryRequest{WalletID: 1})
+		env.SignalWorkflow(TournamentRegisterSignal, TournamentEntryRequest{WalletID: 2})
+		env.SignalWorkflow(TournamentRegisterSignal, TournamentEntryRequest{WalletID: 3})
+	}, time.Minute)
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(StartTournamentSignal, tournament)
+	}, time.Hour)
+
+	env.ExecuteWorkflow(TournamentRegistrationWorkflow, tournament, nil)
+
+	assert.True(t, env.IsWorkflowCompleted())
+	assert.True(t, workflow.IsContinueAsNewError(env.GetWorkflowError()), "Tournament should start, got %v", env.GetWorkflowError())
+	env.AssertActivityNumberOfCalls(t, "BuyInActivity", 3)
+	env.AssertActivityNumberOfCalls(t, "CancelTournamentActivity", 0)
+}
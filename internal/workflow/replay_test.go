@@ -0,0 +1,31 @@
+package temporal
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/worker"
+)
+
+// TestReplayTableHistories replays recorded hands against the current
+// TableWorkflow and fails on any non-deterministic change. The histories are
+// hands of the deployed revision exported from a Temporal server by
+// scripts/record-histories.sh. Record them again after each deploy.
+func TestReplayTableHistories(t *testing.T) {
+	histories, err := filepath.Glob(filepath.Join("testdata", "histories", "*.json"))
+	require.NoError(t, err)
+	if len(histories) == 0 {
+		t.Skip("no histories recorded, run scripts/record-histories.sh")
+	}
+
+	replayer := worker.NewWorkflowReplayer()
+	replayer.RegisterWorkflow(TableWorkflow)
+
+	for _, history := range histories {
+		t.Run(filepath.Base(history), func(t *testing.T) {
+			assert.NoError(t, replayer.ReplayWorkflowHistoryFromJSONFile(nil, history))
+		})
+	}
+}
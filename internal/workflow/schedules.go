@@ -0,0 +1,188 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/config"
+	"server/internal/db/models"
+	"time"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	sdktemporal "go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Steps of a scheduled tournament. Each one is a Temporal schedule that runs
+// TournamentScheduleWorkflow once, at the time stored on the tournament.
+const (
+	OpenRegistrationStep  = "open-registration"
+	CloseRegistrationStep = "close-registration"
+	StartTournamentStep   = "start"
+)
+
+type tournamentStep struct {
+	name string
+	at   time.Time
+}
+
+func tournamentSteps(tournament models.Tournament) []tournamentStep {
+	return []tournamentStep{
+		{OpenRegistrationStep, tournament.RegistrationStartDate},
+		{CloseRegistrationStep, tournament.RegistrationEndDate},
+		{StartTournamentStep, tournament.StartDate},
+	}
+}
+
+func TournamentScheduleID(tournamentID uint, step string) string {
+	return fmt.Sprintf("tournament-%d-%s", tournamentID, step)
+}
+
+// ScheduleTournament creates the schedules that open and close the
+// registration of a tournament and start it. Steps whose time has already
+// passed run right away.
+func ScheduleTournament(ctx context.Context, c client.Client, tournament models.Tournament, cfg *config.Config) error {
+	for _, step := range tournamentSteps(tournament) {
+		if err := scheduleTournamentStep(ctx, c, tournament, step, cfg); err != nil {
+			_ = UnscheduleTournament(ctx, c, tournament.ID)
+			return err
+		}
+	}
+	return nil
+}
+
+func scheduleTournamentStep(ctx context.Context, c client.Client, tournament models.Tournament, step tournamentStep, cfg *config.Config) error {
+	_, err := c.ScheduleClient().Create(ctx, client.ScheduleOptions{
+		ID:                 TournamentScheduleID(tournament.ID, step.name),
+		Spec:               scheduleAt(step.at),
+		Action:             tournamentStepAction(tournament, step.name, cfg),
+		RemainingActions:   1,
+		TriggerImmediately: !step.at.After(time.Now()),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule %s of tournament %d: %w", step.name, tournament.ID, err)
+	}
+	return nil
+}
+
+// RescheduleTournament moves the steps of an edited tournament that have not
+// run yet, and updates the tournament they carry.
+func RescheduleTournament(ctx context.Context, c client.Client, tournament models.Tournament, cfg *config.Config) error {
+	now := time.Now()
+	for _, step := range tournamentSteps(tournament) {
+		handle := c.ScheduleClient().GetHandle(ctx, TournamentScheduleID(tournament.ID, step.name))
+		err := handle.Update(ctx, client.ScheduleUpdateOptions{
+			DoUpdate: func(input client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+				if input.Description.Info.NumActions > 0 {
+					return nil, sdktemporal.ErrSkipScheduleUpdate
+				}
+				schedule := input.Description.Schedule
+				spec := scheduleAt(step.at)
+				schedule.Spec = &spec
+				schedule.Action = tournamentStepAction(tournament, step.name, cfg)
+				schedule.State.LimitedActions = true
+				schedule.State.RemainingActions = 1
+				return &client.ScheduleUpdate{Schedule: &schedule}, nil
+			},
+		})
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			if err := scheduleTournamentStep(ctx, c, tournament, step, cfg); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reschedule %s of tournament %d: %w", step.name, tournament.ID, err)
+		}
+
+		if step.at.After(now) {
+			continue
+		}
+		description, err := handle.Describe(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to describe %s of tournament %d: %w", step.name, tournament.ID, err)
+		}
+		if description.Info.NumActions == 0 {
+			if err := handle.Trigger(ctx, client.ScheduleTriggerOptions{}); err != nil {
+				return fmt.Errorf("failed to trigger %s of tournament %d: %w", step.name, tournament.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// UnscheduleTournament removes the schedules of a tournament. Missing
+// schedules are ignored.
+func UnscheduleTournament(ctx context.Context, c client.Client, tournamentID uint) error {
+	for _, step := range []string{OpenRegistrationStep, CloseRegistrationStep, StartTournamentStep} {
+		err := c.ScheduleClient().GetHandle(ctx, TournamentScheduleID(tournamentID, step)).Delete(ctx)
+		var notFound *serviceerror.NotFound
+		if err != nil && !errors.As(err, &notFound) {
+			return fmt.Errorf("failed to unschedule %s of tournament %d: %w", step, tournamentID, err)
+		}
+	}
+	return nil
+}
+
+// scheduleAt matches the single second at, in UTC.
+func scheduleAt(at time.Time) client.ScheduleSpec {
+	at = at.UTC()
+	value := func(v int) []client.ScheduleRange { return []client.ScheduleRange{{Start: v}} }
+	return client.ScheduleSpec{
+		Calendars: []client.ScheduleCalendarSpec{{
+			Second:     value(at.Second()),
+			Minute:     value(at.Minute()),
+			Hour:       value(at.Hour()),
+			DayOfMonth: value(at.Day()),
+			Month:      value(int(at.Month())),
+			Year:       value(at.Year()),
+		}},
+	}
+}
+
+func tournamentStepAction(tournament models.Tournament, step string, cfg *config.Config) *client.ScheduleWorkflowAction {
+	return &client.ScheduleWorkflowAction{
+		ID:        TournamentScheduleID(tournament.ID, step),
+		Workflow:  TournamentScheduleWorkflow,
+		Args:      []interface{}{tournament, step, cfg},
+		TaskQueue: TaskQueue,
+	}
+}
+
+// TournamentScheduleWorkflow runs one step of a scheduled tournament. Opening
+// the registration starts a TournamentRegistrationWorkflow under the
+// tournament's workflow ID; the other steps signal whichever workflow runs
+// under that ID by then.
+func TournamentScheduleWorkflow(ctx workflow.Context, tournament models.Tournament, step string, config *config.Config) error {
+	workflowID := TournamentWorkflowID(tournament.ID)
+
+	var err error
+	switch step {
+	case OpenRegistrationStep:
+		childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+			WorkflowID:        workflowID,
+			ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON,
+		})
+		child := workflow.ExecuteChildWorkflow(childCtx, TournamentRegistrationWorkflow, tournament, config)
+		err = child.GetChildWorkflowExecution().Get(ctx, nil)
+		if sdktemporal.IsWorkflowExecutionAlreadyStartedError(err) {
+			return nil
+		}
+	case CloseRegistrationStep:
+		err = workflow.SignalExternalWorkflow(ctx, workflowID, "", CloseRegistrationSignal, nil).Get(ctx, nil)
+	case StartTournamentStep:
+		err = workflow.SignalExternalWorkflow(ctx, workflowID, "", StartTournamentSignal, tournament).Get(ctx, nil)
+	default:
+		return sdktemporal.NewNonRetryableApplicationError(fmt.Sprintf("unknown tournament step %q", step), InvalidPayloadError, nil)
+	}
+
+	var unknown *sdktemporal.UnknownExternalWorkflowExecutionError
+	if errors.As(err, &unknown) {
+		workflow.GetLogger(ctx).Warn("Tournament is not taking registrations", "TournamentID", tournament.ID, "Step", step)
+		return nil
+	}
+	return err
+}
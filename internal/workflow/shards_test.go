@@ -0,0 +1,51 @@
+package temporal
+
+import (
+	"errors"
+	"server/config"
+	"server/internal/poker"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestTableTaskQueue(t *testing.T) {
+	assert.Equal(t, TaskQueue, TableTaskQueue(&config.Config{}, "t1"))
+
+	cfg := &config.Config{Temporal: config.TemporalConfig{Shards: []string{"a", "b"}}}
+	queue := TableTaskQueue(cfg, "t1")
+	assert.Contains(t, []string{TaskQueue + "-a", TaskQueue + "-b"}, queue)
+	assert.Equal(t, queue, TableTaskQueue(cfg, "t1"), "A table should always start on the same shard")
+
+	assert.Equal(t, []string{DefaultShard, "a", "b"}, workerShards(cfg))
+	cfg.Temporal.WorkerShards = []string{"b"}
+	assert.Equal(t, []string{"b"}, workerShards(cfg))
+	assert.True(t, IsShard(cfg, DefaultShard))
+	assert.False(t, IsShard(cfg, "c"))
+}
+
+func TestIdleTournamentTableMovesShard(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterActivity(PublishEventActivity)
+	env.OnActivity(PublishEventActivity, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	table, err := poker.NewTable("t1", 6)
+	require.NoError(t, err)
+
+	env.RegisterDelayedCallback(func() {
+		env.SignalWorkflow(ControlSignal, ControlRequest{Action: ControlMove, Shard: "b"})
+	}, time.Minute)
+
+	env.ExecuteWorkflow(TournamentTableWorkflow, table, TournamentWorkflowID(1), (*NextHandRequest)(nil), nil)
+
+	require.True(t, env.IsWorkflowCompleted())
+	var continued *workflow.ContinueAsNewError
+	require.True(t, errors.As(env.GetWorkflowError(), &continued), "Table should continue as new, got %v", env.GetWorkflowError())
+	assert.Equal(t, TaskQueue+"-b", continued.TaskQueueName)
+}
@@ -0,0 +1,57 @@
+package temporal
+
+import (
+	"fmt"
+	"server/config"
+	"server/internal/db/models"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// SitAndGoWorkflow takes registrations for a sit-and-go under the
+// tournament's workflow ID. It starts the tournament as soon as MaxPlayers
+// have registered, or at StartDate with at least MinPlayers. Otherwise the
+// tournament is cancelled and every buy-in refunded, as it is when an admin
+// terminates it.
+func SitAndGoWorkflow(ctx workflow.Context, tournament models.Tournament, config *config.Config) error {
+	ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+	if tournament.MaxPlayers < 2 || tournament.MinPlayers < 2 || tournament.MinPlayers > tournament.MaxPlayers {
+		return fmt.Errorf("invalid player limits %d-%d for tournament %d", tournament.MinPlayers, tournament.MaxPlayers, tournament.ID)
+	}
+
+	s := &registration{tournament: tournament}
+	startDateReached := false
+	var terminateReason string
+
+	selector := workflow.NewSelector(ctx)
+	selector.AddReceive(workflow.GetSignalChannel(ctx, TournamentRegisterSignal), func(c workflow.ReceiveChannel, more bool) {
+		var req TournamentEntryRequest
+		c.Receive(ctx, &req)
+		s.register(ctx, req)
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, ControlSignal), func(c workflow.ReceiveChannel, more bool) {
+		var req ControlRequest
+		c.Receive(ctx, &req)
+		if req.Action == ControlTerminate {
+			terminateReason = controlReason(req, "cancelled by admin")
+		}
+	})
+	if !tournament.StartDate.IsZero() {
+		timer := workflow.NewTimer(ctx, max(tournament.StartDate.Sub(workflow.Now(ctx)), 0))
+		selector.AddFuture(timer, func(f workflow.Future) {
+			startDateReached = f.Get(ctx, nil) == nil
+		})
+	}
+
+	for len(s.registered) < tournament.MaxPlayers && !startDateReached && terminateReason == "" {
+		selector.Select(ctx)
+	}
+
+	if terminateReason != "" {
+		return s.cancel(ctx, terminateReason)
+	}
+	if len(s.registered) < tournament.MinPlayers {
+		return s.cancel(ctx, s.shortMessage())
+	}
+	return workflow.NewContinueAsNewError(ctx, TournamentWorkflow, tournament, (*TournamentState)(nil), config)
+}
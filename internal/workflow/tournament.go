@@ -0,0 +1,654 @@
+package temporal
+
+import (
+	"fmt"
+	"math/rand"
+	"server/config"
+	"server/internal/db/models"
+	"server/internal/poker"
+	"sort"
+	"strconv"
+
+	"go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	HandCompletedSignal = "HandCompleted"
+	NextHandSignal      = "NextHand"
+
+	// tournamentEventsPerRun bounds the history of a tournament run before it
+	// continues as new.
+	tournamentEventsPerRun = 1000
+)
+
+// HandResult is what a tournament table reports to its tournament after
+// every hand.
+type HandResult struct {
+	Table      poker.Table
+	Eliminated []poker.Player
+}
+
+// NextHandRequest tells a tournament table to deal its next hand, or to stop
+// when the tournament is over or the table is broken. Players moved by the
+// tournament leave and arrive, and rebuys and add-ons are added, before the
+// hand is dealt.
+type NextHandRequest struct {
+	Stop       bool
+	Departures []string
+	Arrivals   []poker.Player
+	TopUps     []ChipTopUp
+}
+
+type ChipTopUp struct {
+	PlayerID string
+	Chips    int
+	Bounty   int // added to the player's bounty by a rebuy
+}
+
+// TournamentTable is the tournament's view of one of its tables. Idle tables
+// are between hands, waiting for a NextHandRequest.
+type TournamentTable struct {
+	Table      poker.Table
+	Idle       bool
+	Departures []string
+	Arrivals   []poker.Player
+	TopUps     []ChipTopUp
+}
+
+// TournamentState is carried from one run of a TournamentWorkflow to the next.
+type TournamentState struct {
+	PrizePool  poker.PrizePool
+	Entries    []poker.TournamentEntry
+	Tables     []TournamentTable
+	TableCount int            // tables opened so far, broken ones included
+	Eliminated []poker.Finish // in elimination order
+	Rounds     int            // elimination rounds so far
+
+	// HandForHand holds every table until all of them finish the current
+	// hand. Players busted in the round wait in RoundEliminated.
+	HandForHand     bool
+	RoundEliminated []poker.Player
+
+	// DealPending holds the tables until a proposed deal is answered. Deal
+	// is the deal the players accepted, if any.
+	DealPending *ProposeDealRequest
+	Deal        *poker.Deal
+
+	// PausedReason holds every table after its current hand. Once
+	// TerminateReason is set the tournament ends as soon as every table is
+	// idle, ranking the survivors by chips.
+	PausedReason    string
+	TerminateReason string
+
+	// RegistrationClosed rejects late registrations and re-entries once the
+	// registration of a scheduled tournament has closed.
+	RegistrationClosed bool
+}
+
+func TournamentWorkflowID(tournamentID uint) string {
+	return fmt.Sprintf("tournament-%d", tournamentID)
+}
+
+func tournamentKey(tournament models.Tournament) string {
+	return strconv.FormatUint(uint64(tournament.ID), 10)
+}
+
+// TournamentWorkflow runs a tournament from the draw to the final ranking.
+// Each table is a child TournamentTableWorkflow that reports back after every
+// hand and waits for the tournament before dealing the next one. state is nil
+// on the first run.
+func TournamentWorkflow(ctx workflow.Context, tournament models.Tournament, state *TournamentState, config *config.Config) (TournamentState, error) {
+	ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+
+	if state == nil {
+		started, err := startTournament(ctx, tournament, config)
+		if err != nil {
+			return TournamentState{}, err
+		}
+		state = &started
+	}
+	t := &tournamentRun{tournament: tournament, state: *state, config: config}
+
+	selector := workflow.NewSelector(ctx)
+	selector.AddReceive(workflow.GetSignalChannel(ctx, HandCompletedSignal), func(c workflow.ReceiveChannel, more bool) {
+		var result HandResult
+		c.Receive(ctx, &result)
+		t.handCompleted(ctx, result)
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, TournamentRegisterSignal), func(c workflow.ReceiveChannel, more bool) {
+		var req TournamentEntryRequest
+		c.Receive(ctx, &req)
+		t.register(ctx, req)
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, CloseRegistrationSignal), func(c workflow.ReceiveChannel, more bool) {
+		c.Receive(ctx, nil)
+		t.state.RegistrationClosed = true
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, RebuySignal), func(c workflow.ReceiveChannel, more bool) {
+		var req TournamentEntryRequest
+		c.Receive(ctx, &req)
+		t.rebuy(ctx, req)
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, AddOnSignal), func(c workflow.ReceiveChannel, more bool) {
+		var req TournamentEntryRequest
+		c.Receive(ctx, &req)
+		t.addOn(ctx, req)
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, ProposeDealSignal), func(c workflow.ReceiveChannel, more bool) {
+		var req ProposeDealRequest
+		c.Receive(ctx, &req)
+		t.proposeDeal(ctx, req)
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, ControlSignal), func(c workflow.ReceiveChannel, more bool) {
+		var req ControlRequest
+		c.Receive(ctx, &req)
+		t.control(ctx, req)
+	})
+
+	for events := 0; events < tournamentEventsPerRun && !t.finished(); events++ {
+		selector.Select(ctx)
+	}
+	for !t.finished() && selector.HasPending() {
+		selector.Select(ctx)
+	}
+
+	if t.finished() {
+		return t.state, t.finish(ctx)
+	}
+	return t.state, workflow.NewContinueAsNewError(ctx, TournamentWorkflow, tournament, &t.state, config)
+}
+
+// startTournament draws the seats, starts the clock and deals the first hand
+// on every table.
+func startTournament(ctx workflow.Context, tournament models.Tournament, config *config.Config) (TournamentState, error) {
+	var state TournamentState
+	if err := tournament.Configuration.Blinds.Validate(); err != nil {
+		return state, err
+	}
+
+	var players []poker.Player
+	if err := workflow.ExecuteActivity(ctx, StartTournamentActivity, tournament.ID).Get(ctx, &players); err != nil {
+		return state, err
+	}
+	for _, player := range players {
+		state.Entries = append(state.Entries, poker.TournamentEntry{
+			WalletID: player.WalletID,
+			Entry:    player.Entry,
+			Chips:    tournament.Configuration.StartingChips,
+		})
+	}
+	pool, err := prizePool(tournament.Configuration, state.Entries)
+	if err != nil {
+		return state, err
+	}
+	state.PrizePool = pool
+	state.RegistrationClosed = !tournament.Configuration.SitAndGo && !tournament.RegistrationEndDate.IsZero() &&
+		!workflow.Now(ctx).Before(tournament.RegistrationEndDate)
+
+	var seed int64
+	encoded := workflow.SideEffect(ctx, func(ctx workflow.Context) interface{} {
+		return rand.Int63()
+	})
+	if err := encoded.Get(&seed); err != nil {
+		return state, err
+	}
+	draw := rand.New(rand.NewSource(seed))
+	draw.Shuffle(len(players), func(i, j int) { players[i], players[j] = players[j], players[i] })
+
+	tables, err := poker.SeatTournament(tournamentKey(tournament), players, tournament.TableSize, tournament.Configuration.StartingChips)
+	if err != nil {
+		return state, err
+	}
+	for i := range tables {
+		tables[i].TurnTime = tournament.TurnSeconds
+		tables[i].ProgressiveBounty = tournament.Configuration.ProgressiveBounty
+		for _, player := range tables[i].SeatedPlayers() {
+			player.Bounty = tournament.Configuration.Bounty
+		}
+	}
+	if err := workflow.ExecuteActivity(withHeartbeat(ctx), SaveSeatingActivity, tournament.ID, tables).Get(ctx, nil); err != nil {
+		return state, err
+	}
+
+	clockCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:        TournamentClockWorkflowID(tournamentKey(tournament)),
+		ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON,
+	})
+	clock := workflow.ExecuteChildWorkflow(clockCtx, TournamentClockWorkflow, tournamentKey(tournament), tournament.Configuration.Blinds, 0)
+	if err := clock.GetChildWorkflowExecution().Get(ctx, nil); err != nil {
+		return state, err
+	}
+
+	for _, table := range tables {
+		if err := startTournamentTable(ctx, tournament, table, &NextHandRequest{}, config); err != nil {
+			return state, err
+		}
+		state.Tables = append(state.Tables, TournamentTable{Table: table})
+	}
+	state.TableCount = len(tables)
+	return state, nil
+}
+
+// startTournamentTable starts the child workflow of a table. Tables outlive a
+// single run of the tournament, so they are abandoned on continue-as-new and
+// addressed by workflow ID.
+func startTournamentTable(ctx workflow.Context, tournament models.Tournament, table poker.Table, next *NextHandRequest, config *config.Config) error {
+	tableCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:        TableWorkflowID(table.ID),
+		TaskQueue:         TableTaskQueue(config, table.ID),
+		ParentClosePolicy: enums.PARENT_CLOSE_POLICY_ABANDON,
+	})
+	child := workflow.ExecuteChildWorkflow(tableCtx, TournamentTableWorkflow, table, TournamentWorkflowID(tournament.ID), next, config)
+	return child.GetChildWorkflowExecution().Get(ctx, nil)
+}
+
+func prizePool(cfg poker.TournamentConfig, entries []poker.TournamentEntry) (poker.PrizePool, error) {
+	rebuys, addOns := 0, 0
+	for _, entry := range entries {
+		rebuys += entry.Rebuys
+		if entry.AddOn {
+			addOns++
+		}
+	}
+	return poker.CalculatePrizePool(cfg, len(entries), rebuys, addOns)
+}
+
+type tournamentRun struct {
+	tournament models.Tournament
+	state      TournamentState
+	config     *config.Config
+}
+
+func (t *tournamentRun) remainingPlayers() int {
+	count := 0
+	for _, table := range t.state.Tables {
+		count += table.Table.PlayerCount()
+	}
+	return count
+}
+
+// finished reports whether one player is left, a deal split everything or
+// the tournament was terminated and no hand is in play.
+func (t *tournamentRun) finished() bool {
+	if t.state.Deal != nil && t.state.Deal.Remainder == 0 {
+		return true
+	}
+	if t.state.TerminateReason != "" && t.allIdle() {
+		return true
+	}
+	return t.remainingPlayers() <= 1
+}
+
+func (t *tournamentRun) tableIndex(tableID string) int {
+	for i, table := range t.state.Tables {
+		if table.Table.ID == tableID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (t *tournamentRun) handCompleted(ctx workflow.Context, result HandResult) {
+	index := t.tableIndex(result.Table.ID)
+	if index == -1 {
+		workflow.GetLogger(ctx).Warn("Hand result from unknown table", "TableID", result.Table.ID)
+		return
+	}
+	table := &t.state.Tables[index]
+	table.Table = result.Table
+	table.Idle = true
+	// Players moved here and chips bought during the hand are not on the
+	// reported table yet.
+	for _, player := range table.Arrivals {
+		_ = table.Table.SitPlayer(player.Seat, player)
+	}
+	for _, topUp := range table.TopUps {
+		if player := table.Table.FindPlayer(topUp.PlayerID); player != nil {
+			player.Chips += topUp.Chips
+			player.Bounty += topUp.Bounty
+		}
+	}
+	for _, player := range result.Eliminated {
+		if t.keepToppedUp(ctx, table, player) {
+			continue
+		}
+		if entry := t.entry(player.WalletID); entry != nil {
+			entry.Busted = true
+		}
+		t.state.RoundEliminated = append(t.state.RoundEliminated, player)
+	}
+
+	if t.state.HandForHand && !t.allIdle() && !t.finished() {
+		return
+	}
+	t.closeRound(ctx)
+	if t.finished() || t.state.TerminateReason != "" {
+		return
+	}
+	if t.state.DealPending != nil {
+		if !t.allIdle() {
+			return
+		}
+		t.offerDeal(ctx)
+		if t.finished() {
+			return
+		}
+	}
+	t.updateHandForHand(ctx)
+	t.balance(ctx)
+	t.resumeIdleTables(ctx)
+}
+
+func (t *tournamentRun) allIdle() bool {
+	for _, table := range t.state.Tables {
+		if !table.Idle {
+			return false
+		}
+	}
+	return true
+}
+
+// closeRound ranks the players busted since the last round, stores their
+// positions and announces them.
+func (t *tournamentRun) closeRound(ctx workflow.Context) {
+	if len(t.state.RoundEliminated) == 0 {
+		return
+	}
+	t.state.Rounds++
+	finishes := poker.RankRound(t.state.RoundEliminated, t.state.Rounds, t.remainingPlayers())
+	t.state.Eliminated = append(t.state.Eliminated, finishes...)
+	t.state.RoundEliminated = nil
+
+	rankings := make([]models.Ranking, len(finishes))
+	for i, finish := range finishes {
+		rankings[i] = t.ranking(finish.Player, finish.Position, 0)
+	}
+	err := workflow.ExecuteActivity(ctx, RecordEliminationsActivity, t.tournament.ID, rankings).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Error("Failed to record eliminations", "TournamentID", t.tournament.ID, "Error", err)
+	}
+
+	for _, finish := range finishes {
+		event := poker.TableEvent{
+			Type:     "playerEliminated",
+			PlayerID: finish.Player.ID,
+			Data: map[string]int{
+				"position":  finish.Position,
+				"remaining": t.remainingPlayers(),
+				"entry":     finish.Player.Entry,
+			},
+		}
+		err := workflow.ExecuteActivity(ctx, PublishEventActivity, poker.TournamentSubject(tournamentKey(t.tournament)), event).Get(ctx, nil)
+		if err != nil {
+			workflow.GetLogger(ctx).Warn("Failed to announce elimination", "TournamentID", t.tournament.ID, "PlayerID", finish.Player.ID, "Error", err)
+		}
+	}
+}
+
+// updateHandForHand plays hand-for-hand on the bubble, while one more
+// elimination puts everybody left in the money.
+func (t *tournamentRun) updateHandForHand(ctx workflow.Context) {
+	places := len(t.state.PrizePool.Prizes)
+	bubble := len(t.state.Tables) > 1 && places > 0 && t.remainingPlayers() == places+1
+	if bubble == t.state.HandForHand {
+		return
+	}
+	t.state.HandForHand = bubble
+
+	for _, table := range t.state.Tables {
+		notifyTable(ctx, &table.Table, poker.TableEvent{
+			Type:    "handForHand",
+			TableID: table.Table.ID,
+			Data:    map[string]bool{"active": bubble},
+		})
+	}
+}
+
+// balance moves players off idle tables so table sizes stay within one of
+// each other, breaking tables the field no longer fills.
+func (t *tournamentRun) balance(ctx workflow.Context) {
+	tables := make([]*poker.Table, len(t.state.Tables))
+	idle := make(map[*poker.Table]bool)
+	for i := range t.state.Tables {
+		tables[i] = &t.state.Tables[i].Table
+		idle[tables[i]] = t.state.Tables[i].Idle
+	}
+
+	moves := poker.PlanBalance(tables, func(table *poker.Table) bool { return idle[table] })
+	if len(moves) == 0 {
+		return
+	}
+
+	for _, move := range moves {
+		from := &t.state.Tables[t.tableIndex(move.FromTable)]
+		if !from.cancelArrival(move.PlayerID) {
+			from.Departures = append(from.Departures, move.PlayerID)
+		}
+		to := &t.state.Tables[t.tableIndex(move.ToTable)]
+		to.Arrivals = append(to.Arrivals, *to.Table.PlayerAt(move.Seat))
+	}
+
+	err := workflow.ExecuteActivity(ctx, MoveSeatsActivity, t.tournament.ID, moves).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Error("Failed to save seat moves", "TournamentID", t.tournament.ID, "Error", err)
+	}
+	for _, move := range moves {
+		event := poker.TableEvent{
+			Type:     "tableMove",
+			TableID:  move.FromTable,
+			PlayerID: move.PlayerID,
+			Data:     map[string]interface{}{"tableId": move.ToTable, "seat": move.Seat},
+		}
+		err := workflow.ExecuteActivity(ctx, PublishEventActivity, poker.PlayerSubject(move.FromTable, move.PlayerID), event).Get(ctx, nil)
+		if err != nil {
+			workflow.GetLogger(ctx).Warn("Failed to notify table move", "PlayerID", move.PlayerID, "Error", err)
+		}
+	}
+}
+
+// cancelArrival drops a player whose move here has not been delivered yet,
+// reporting whether there was one.
+func (table *TournamentTable) cancelArrival(playerID string) bool {
+	for i, player := range table.Arrivals {
+		if player.ID == playerID {
+			table.Arrivals = append(table.Arrivals[:i], table.Arrivals[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// resumeIdleTables hands every idle table its pending moves. Tables left
+// empty are stopped and dropped; the others deal their next hand as soon as
+// they have two players. Hand-for-hand, nothing resumes until every table is
+// idle.
+func (t *tournamentRun) resumeIdleTables(ctx workflow.Context) {
+	if t.state.DealPending != nil || t.state.PausedReason != "" || t.state.TerminateReason != "" {
+		return
+	}
+	if t.state.HandForHand && !t.allIdle() {
+		return
+	}
+	remaining := t.state.Tables[:0]
+	for _, table := range t.state.Tables {
+		if !table.Idle {
+			remaining = append(remaining, table)
+			continue
+		}
+
+		req := NextHandRequest{Departures: table.Departures, Arrivals: table.Arrivals, TopUps: table.TopUps}
+		switch {
+		case table.Table.PlayerCount() == 0:
+			req.Stop = true
+			t.nextHand(ctx, table.Table.ID, req)
+			continue
+		case table.Table.ReadyPlayerCount() >= 2:
+			t.nextHand(ctx, table.Table.ID, req)
+			table.Idle = false
+			table.Departures = nil
+			table.Arrivals = nil
+			table.TopUps = nil
+		}
+		remaining = append(remaining, table)
+	}
+	t.state.Tables = remaining
+}
+
+func (t *tournamentRun) nextHand(ctx workflow.Context, tableID string, req NextHandRequest) {
+	err := workflow.SignalExternalWorkflow(ctx, TableWorkflowID(tableID), "", NextHandSignal, req).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to signal tournament table", "TableID", tableID, "Error", err)
+	}
+}
+
+// finish stops the tables and the clock and records the final ranking.
+func (t *tournamentRun) finish(ctx workflow.Context) error {
+	for _, table := range t.state.Tables {
+		t.nextHand(ctx, table.Table.ID, NextHandRequest{Stop: true})
+	}
+	err := workflow.RequestCancelExternalWorkflow(ctx, TournamentClockWorkflowID(tournamentKey(t.tournament)), "").Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to stop tournament clock", "TournamentID", t.tournament.ID, "Error", err)
+	}
+
+	return workflow.ExecuteActivity(withHeartbeat(ctx), FinishTournamentActivity, t.tournament.ID, t.state.PrizePool, t.rankings()).Get(ctx, nil)
+}
+
+// rankings orders the survivors by chips and then the eliminated players from
+// the last one out to the first, with the prize of each position and the
+// bounties won. Players tied on a position split the prizes it covers.
+// Survivors also keep the bounty on their own head.
+func (t *tournamentRun) rankings() []models.Ranking {
+	var survivors []poker.Player
+	for _, table := range t.state.Tables {
+		for _, player := range table.Table.SeatedPlayers() {
+			survivors = append(survivors, *player)
+		}
+	}
+	sort.SliceStable(survivors, func(i, j int) bool { return survivors[i].Chips > survivors[j].Chips })
+
+	rankings := make([]models.Ranking, 0, len(survivors)+len(t.state.Eliminated))
+	for _, player := range survivors {
+		player.BountiesWon += player.Bounty
+		position := len(rankings) + 1
+		rankings = append(rankings, t.ranking(player, position, t.prize(player, position, t.state.PrizePool.Prize(position))))
+	}
+
+	finishes := poker.RerankFinishes(t.state.Eliminated, len(survivors))
+	tied := make(map[int]int)
+	for i := len(finishes) - 1; i >= 0; i-- {
+		finish := finishes[i]
+		prize := t.state.PrizePool.SharedPrize(finish.Position, finish.Places, tied[finish.Position])
+		tied[finish.Position]++
+		rankings = append(rankings, t.ranking(finish.Player, finish.Position, t.prize(finish.Player, finish.Position, prize)))
+	}
+	return rankings
+}
+
+func (t *tournamentRun) ranking(player poker.Player, position int, prize int) models.Ranking {
+	return models.Ranking{
+		TournamentID: t.tournament.ID,
+		WalletID:     player.WalletID,
+		Entry:        player.Entry,
+		Position:     position,
+		Prize:        prize,
+		Bounties:     player.BountiesWon,
+	}
+}
+
+// TournamentTableWorkflow plays one hand on a tournament table once the
+// tournament asks for it, reports the result and continues as new for the
+// next hand. next is the instruction already received for this hand, if any.
+func TournamentTableWorkflow(ctx workflow.Context, table poker.Table, tournamentWorkflowID string, next *NextHandRequest, config *config.Config) (poker.Table, error) {
+	ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+	nextCh := workflow.GetSignalChannel(ctx, NextHandSignal)
+	if err := registerTableQueries(ctx, &table, nil); err != nil {
+		return table, err
+	}
+
+	if next == nil {
+		next = awaitNextHand(ctx, nextCh, &table)
+		if next == nil {
+			ctx = moveTable(ctx, &table)
+			return table, workflow.NewContinueAsNewError(ctx, TournamentTableWorkflow, table, tournamentWorkflowID, next, config)
+		}
+	}
+	for _, playerID := range next.Departures {
+		table.StandUp(playerID)
+	}
+	if next.Stop {
+		return table, nil
+	}
+	for _, player := range next.Arrivals {
+		if err := table.SitPlayer(player.Seat, player); err != nil {
+			workflow.GetLogger(ctx).Error("Failed to seat moved player", "TableID", table.ID, "PlayerID", player.ID, "Error", err)
+		}
+	}
+	for _, topUp := range next.TopUps {
+		if player := table.FindPlayer(topUp.PlayerID); player != nil {
+			player.Chips += topUp.Chips
+			player.Bounty += topUp.Bounty
+		}
+	}
+
+	// Ending a tournament table is up to the tournament: once terminated the
+	// table deals no more hands and only reports back until it is stopped.
+	drainControl(ctx, &table)
+	awaitResume(ctx, &table)
+	if table.TerminateReason == "" {
+		var err error
+		table, err = TableWorkflow(ctx, table, config)
+		if err != nil {
+			return table, err
+		}
+		if err := registerTableQueries(ctx, &table, nil); err != nil {
+			return table, err
+		}
+		drainControl(ctx, &table)
+		awaitResume(ctx, &table)
+	}
+
+	result := HandResult{Eliminated: table.StandUpEliminated()}
+	result.Table = table
+	err := workflow.SignalExternalWorkflow(ctx, tournamentWorkflowID, "", HandCompletedSignal, result).Get(ctx, nil)
+	if err != nil {
+		return table, err
+	}
+	drainControl(ctx, &table)
+
+	// The answer may arrive before this run closes; carry it over so it is
+	// not lost.
+	var pending *NextHandRequest
+	var req NextHandRequest
+	if nextCh.ReceiveAsync(&req) {
+		pending = &req
+	}
+	if table.MoveToShard != "" {
+		ctx = moveTable(ctx, &table)
+	}
+	return table, workflow.NewContinueAsNewError(ctx, TournamentTableWorkflow, table, tournamentWorkflowID, pending, config)
+}
+
+// awaitNextHand waits for the tournament to ask for the next hand. It returns
+// nil when the table is moved to another shard in the meantime, so an idle
+// table does not hold on to its old shard.
+func awaitNextHand(ctx workflow.Context, nextCh workflow.ReceiveChannel, table *poker.Table) *NextHandRequest {
+	var next *NextHandRequest
+	controlCh := workflow.GetSignalChannel(ctx, ControlSignal)
+	for next == nil && table.MoveToShard == "" {
+		selector := workflow.NewSelector(ctx)
+		selector.AddReceive(nextCh, func(c workflow.ReceiveChannel, _ bool) {
+			var req NextHandRequest
+			c.Receive(ctx, &req)
+			next = &req
+		})
+		selector.AddReceive(controlCh, func(c workflow.ReceiveChannel, _ bool) {
+			var req ControlRequest
+			c.Receive(ctx, &req)
+			applyControl(ctx, table, req)
+		})
+		selector.Select(ctx)
+	}
+	return next
+}
@@ -0,0 +1,116 @@
+package temporal
+
+import (
+	"fmt"
+	"server/internal/poker"
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+// PlayerActionSignal delivers a poker.PlayerAction to the workflow that owns
+// the table, TableWorkflowID. The bridge forwards them from NATS.
+const PlayerActionSignal = "PlayerAction"
+
+// playBettingRound plays the betting round of the current stage one turn at
+// a time. Actions arrive as signals and the turn clock is a workflow timer, so
+// every decision is recorded in the workflow history. It returns a non-empty
+// reason when the hand has to be voided.
+func playBettingRound(ctx workflow.Context, voidCh workflow.ReceiveChannel, table *poker.Table) string {
+	more, err := table.StartBettingRound()
+	if err != nil {
+		return fmt.Sprintf("hand step failed: %v", err)
+	}
+
+	actionCh := workflow.GetSignalChannel(ctx, PlayerActionSignal)
+	for more {
+		dropStaleActions(ctx, actionCh)
+		table.EndTime = int(workflow.Now(ctx).Unix()) + table.TurnTime
+		if reason := runHandStep(ctx, voidCh, nil, SendTableUpdateActivity, table); reason != "" {
+			return reason
+		}
+
+		var reason string
+		more, reason = awaitTurn(ctx, voidCh, actionCh, table)
+		if reason != "" {
+			return reason
+		}
+	}
+
+	workflow.GetLogger(ctx).Info("Los turnos de los jugadores se han completado", "TableID", table.ID, "Stage", table.CurrentStage)
+	return ""
+}
+
+// awaitTurn waits for the player whose turn it is to act, or for the turn
+// time to run out. Actions from anyone else, or that the player cannot take,
+// are ignored. An immediate pause freezes the turn clock until the table is
+// resumed.
+func awaitTurn(ctx workflow.Context, voidCh, actionCh workflow.ReceiveChannel, table *poker.Table) (bool, string) {
+	controlCh := workflow.GetSignalChannel(ctx, ControlSignal)
+	timerCtx, cancelTimer := workflow.WithCancel(ctx)
+	defer func() { cancelTimer() }()
+	timer := workflow.NewTimer(timerCtx, time.Duration(table.TurnTime)*time.Second)
+
+	var more, acted bool
+	var reason string
+	for !acted && reason == "" {
+		selector := workflow.NewSelector(ctx)
+		selector.AddReceive(actionCh, func(c workflow.ReceiveChannel, _ bool) {
+			var action poker.PlayerAction
+			c.Receive(ctx, &action)
+			next, err := table.ApplyAction(action)
+			if err != nil {
+				workflow.GetLogger(ctx).Warn("Ignoring player action", "TableID", table.ID, "PlayerID", action.PlayerID, "Action", action.Action, "Error", err)
+				return
+			}
+			more, acted = next, true
+		})
+		selector.AddFuture(timer, func(f workflow.Future) {
+			workflow.GetLogger(ctx).Info("El tiempo de turno ha expirado", "TableID", table.ID, "PlayerID", table.CurrentTurn)
+			more, acted = table.TimeoutTurn(), true
+		})
+		selector.AddReceive(voidCh, func(c workflow.ReceiveChannel, _ bool) {
+			var req VoidHandRequest
+			c.Receive(ctx, &req)
+			reason = req.Reason
+			if reason == "" {
+				reason = "voided by admin"
+			}
+		})
+		selector.AddReceive(controlCh, func(c workflow.ReceiveChannel, _ bool) {
+			var req ControlRequest
+			c.Receive(ctx, &req)
+			if req.Action != ControlPause || !req.Immediate {
+				applyControl(ctx, table, req)
+				return
+			}
+
+			left := remainingTurn(ctx, table)
+			cancelTimer()
+			if reason = freezeTurn(ctx, voidCh, controlCh, table, req); reason != "" {
+				return
+			}
+			dropStaleActions(ctx, actionCh)
+			table.EndTime = int(workflow.Now(ctx).Unix()) + int(left.Seconds())
+			if reason = runHandStep(ctx, voidCh, nil, SendTableUpdateActivity, table); reason != "" {
+				return
+			}
+			timerCtx, cancelTimer = workflow.WithCancel(ctx)
+			timer = workflow.NewTimer(timerCtx, left)
+		})
+		selector.Select(ctx)
+	}
+	return more, reason
+}
+
+// dropStaleActions discards actions received before the current turn was
+// announced, such as a double click on the previous one.
+func dropStaleActions(ctx workflow.Context, actionCh workflow.ReceiveChannel) {
+	for {
+		var action poker.PlayerAction
+		if !actionCh.ReceiveAsync(&action) {
+			return
+		}
+		workflow.GetLogger(ctx).Warn("Dropping stale player action", "PlayerID", action.PlayerID, "Action", action.Action)
+	}
+}
@@ -0,0 +1,16 @@
+package temporal
+
+// Change IDs passed to workflow.GetVersion. Workers are deployed one at a
+// time while hands are in progress, so any change to the commands a workflow
+// issues (activities, timers, side effects, child workflows) goes behind a new
+// change ID. Executions that started on older code replay the DefaultVersion
+// branch. The old branch and its change ID can only be removed once no open
+// execution recorded it.
+//
+// Run the replay tests in replay_test.go before deploying.
+const (
+	// workflowTurnsChange plays the betting rounds in TableWorkflow, one
+	// PlayerActionSignal at a time, instead of in the HandleTurns activity.
+	// Hands started before replay legacyTableWorkflow.
+	workflowTurnsChange = "workflow-turns"
+)
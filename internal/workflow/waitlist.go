@@ -0,0 +1,275 @@
+package temporal
+
+import (
+	"server/internal/poker"
+	"time"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	JoinWaitingListSignal  = "JoinWaitingList"
+	LeaveWaitingListSignal = "LeaveWaitingList"
+	SeatOpenedSignal       = "SeatOpened"
+	AcceptSeatSignal       = "AcceptSeat"
+	DeclineSeatSignal      = "DeclineSeat"
+	ReserveSeatSignal      = "ReserveSeat"
+
+	WaitingListStateQuery = "WaitingListState"
+
+	// waitingListEventsPerRun bounds the history of a waiting list run before
+	// it continues as new.
+	waitingListEventsPerRun = 500
+)
+
+type LeaveWaitingListRequest struct {
+	PlayerID string
+}
+
+type SeatOpenedRequest struct {
+	TableID  string
+	Seat     int
+	StakeKey string
+}
+
+type AcceptSeatRequest struct {
+	PlayerID  string
+	BuyIn     int
+	WaitForBB bool
+}
+
+type DeclineSeatRequest struct {
+	PlayerID string
+}
+
+// ReserveSeatRequest holds a seat on a table for PlayerID, or releases it
+// when PlayerID is empty.
+type ReserveSeatRequest struct {
+	Seat     int
+	PlayerID string
+}
+
+func WaitingListWorkflowID(key string) string {
+	return "waiting-list-" + key
+}
+
+// WaitingListWorkflow keeps the line for one table or stake. It is started
+// with the first join and completes once nobody is waiting and no offer is
+// pending.
+func WaitingListWorkflow(ctx workflow.Context, list poker.WaitingList, reservation time.Duration) (poker.WaitingList, error) {
+	ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+	w := &waitingList{
+		list:        list,
+		reservation: reservation,
+		timers:      make(map[string]workflow.CancelFunc),
+		expired:     workflow.NewChannel(ctx),
+	}
+
+	err := workflow.SetQueryHandler(ctx, WaitingListStateQuery, func() (poker.WaitingList, error) {
+		return w.list, nil
+	})
+	if err != nil {
+		return list, err
+	}
+
+	// Offers carried over from a previous run keep their original deadline.
+	for _, offer := range w.list.Offers {
+		w.startTimer(ctx, offer)
+	}
+
+	joinCh := workflow.GetSignalChannel(ctx, JoinWaitingListSignal)
+	leaveCh := workflow.GetSignalChannel(ctx, LeaveWaitingListSignal)
+	openedCh := workflow.GetSignalChannel(ctx, SeatOpenedSignal)
+	acceptCh := workflow.GetSignalChannel(ctx, AcceptSeatSignal)
+	declineCh := workflow.GetSignalChannel(ctx, DeclineSeatSignal)
+
+	selector := workflow.NewSelector(ctx)
+	selector.AddReceive(joinCh, func(c workflow.ReceiveChannel, more bool) {
+		var entry poker.WaitingEntry
+		c.Receive(ctx, &entry)
+		w.join(ctx, entry)
+	})
+	selector.AddReceive(leaveCh, func(c workflow.ReceiveChannel, more bool) {
+		var req LeaveWaitingListRequest
+		c.Receive(ctx, &req)
+		w.leave(ctx, req.PlayerID)
+	})
+	selector.AddReceive(openedCh, func(c workflow.ReceiveChannel, more bool) {
+		var req SeatOpenedRequest
+		c.Receive(ctx, &req)
+		w.offerSeat(ctx, req)
+	})
+	selector.AddReceive(acceptCh, func(c workflow.ReceiveChannel, more bool) {
+		var req AcceptSeatRequest
+		c.Receive(ctx, &req)
+		w.accept(ctx, req)
+	})
+	selector.AddReceive(declineCh, func(c workflow.ReceiveChannel, more bool) {
+		var req DeclineSeatRequest
+		c.Receive(ctx, &req)
+		w.withdraw(ctx, req.PlayerID, "seatDeclined")
+	})
+	selector.AddReceive(w.expired, func(c workflow.ReceiveChannel, more bool) {
+		var expiry offerExpiry
+		c.Receive(ctx, &expiry)
+		// A timer that fired as its offer was replaced must not withdraw the new one.
+		if offer, ok := w.list.FindOffer(expiry.PlayerID); !ok || !offer.ExpiresAt.Equal(expiry.ExpiresAt) {
+			return
+		}
+		w.withdraw(ctx, expiry.PlayerID, "seatOfferExpired")
+	})
+
+	for events := 0; events < waitingListEventsPerRun; events++ {
+		selector.Select(ctx)
+		if w.list.IsIdle() && !selector.HasPending() {
+			return w.list, nil
+		}
+	}
+
+	for selector.HasPending() {
+		selector.Select(ctx)
+	}
+	return w.list, workflow.NewContinueAsNewError(ctx, WaitingListWorkflow, w.list, reservation)
+}
+
+type waitingList struct {
+	list        poker.WaitingList
+	reservation time.Duration
+	timers      map[string]workflow.CancelFunc
+	expired     workflow.Channel
+}
+
+func (w *waitingList) join(ctx workflow.Context, entry poker.WaitingEntry) {
+	entry.JoinedAt = workflow.Now(ctx)
+	if err := w.list.Join(entry); err != nil {
+		w.notify(ctx, entry.PlayerID, poker.TableEvent{Type: "waitingListRejected", Message: err.Error()})
+		return
+	}
+	w.publishPositions(ctx)
+}
+
+func (w *waitingList) leave(ctx workflow.Context, playerID string) {
+	offer, hadOffer := w.list.FindOffer(playerID)
+	if !w.list.Leave(playerID) {
+		return
+	}
+	if hadOffer {
+		w.stopTimer(playerID)
+		w.offerSeat(ctx, SeatOpenedRequest{TableID: offer.TableID, Seat: offer.Seat, StakeKey: offer.StakeKey})
+	}
+	w.notify(ctx, playerID, poker.TableEvent{Type: "waitingListLeft"})
+	w.publishPositions(ctx)
+}
+
+// offerSeat hands the seat to the next player in line. With nobody waiting on
+// a table list the seat is passed on to the list of its stake, and released
+// if that list does not exist either.
+func (w *waitingList) offerSeat(ctx workflow.Context, req SeatOpenedRequest) {
+	offer, ok := w.list.Offer(req.TableID, req.Seat, req.StakeKey, workflow.Now(ctx).Add(w.reservation))
+	if !ok {
+		if req.StakeKey != "" && req.StakeKey != w.list.Key {
+			err := workflow.SignalExternalWorkflow(ctx, WaitingListWorkflowID(req.StakeKey), "", SeatOpenedSignal, req).Get(ctx, nil)
+			if err == nil {
+				return
+			}
+		}
+		w.reserveSeat(ctx, req.TableID, req.Seat, "")
+		return
+	}
+
+	w.reserveSeat(ctx, req.TableID, req.Seat, offer.PlayerID)
+	w.startTimer(ctx, offer)
+	w.notify(ctx, offer.PlayerID, poker.TableEvent{Type: "seatOffered", TableID: offer.TableID, Data: offer})
+	w.publishPositions(ctx)
+}
+
+func (w *waitingList) accept(ctx workflow.Context, req AcceptSeatRequest) {
+	offer, ok := w.list.TakeOffer(req.PlayerID)
+	if !ok {
+		w.notify(ctx, req.PlayerID, poker.TableEvent{Type: "seatOfferMissing", Message: "no pending seat offer"})
+		return
+	}
+	w.stopTimer(req.PlayerID)
+
+	join := JoinTableRequest{
+		Player:    poker.Player{ID: offer.PlayerID, WalletID: offer.WalletID},
+		Seat:      offer.Seat,
+		BuyIn:     req.BuyIn,
+		WaitForBB: req.WaitForBB,
+	}
+	err := workflow.SignalExternalWorkflow(ctx, TableWorkflowID(offer.TableID), "", JoinTableSignal, join).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to seat player from waiting list", "TableID", offer.TableID, "PlayerID", offer.PlayerID, "Error", err)
+		w.notify(ctx, offer.PlayerID, poker.TableEvent{Type: "seatOfferMissing", TableID: offer.TableID, Message: err.Error()})
+		w.offerSeat(ctx, SeatOpenedRequest{TableID: offer.TableID, Seat: offer.Seat, StakeKey: offer.StakeKey})
+		return
+	}
+	w.notify(ctx, offer.PlayerID, poker.TableEvent{Type: "seatAccepted", TableID: offer.TableID, Data: offer})
+}
+
+// withdraw drops a declined or expired offer and moves the seat down the line.
+func (w *waitingList) withdraw(ctx workflow.Context, playerID string, eventType string) {
+	offer, ok := w.list.TakeOffer(playerID)
+	if !ok {
+		return
+	}
+	w.stopTimer(playerID)
+	w.notify(ctx, playerID, poker.TableEvent{Type: eventType, TableID: offer.TableID})
+	w.offerSeat(ctx, SeatOpenedRequest{TableID: offer.TableID, Seat: offer.Seat, StakeKey: offer.StakeKey})
+}
+
+// offerExpiry tells the workflow loop which offer a timer was started for.
+type offerExpiry struct {
+	PlayerID  string
+	ExpiresAt time.Time
+}
+
+func (w *waitingList) startTimer(ctx workflow.Context, offer poker.SeatOffer) {
+	w.stopTimer(offer.PlayerID)
+	timerCtx, cancel := workflow.WithCancel(ctx)
+	w.timers[offer.PlayerID] = cancel
+	expiry := offerExpiry{PlayerID: offer.PlayerID, ExpiresAt: offer.ExpiresAt}
+	d := offer.ExpiresAt.Sub(workflow.Now(ctx))
+	if d < 0 {
+		d = 0
+	}
+	workflow.Go(timerCtx, func(gCtx workflow.Context) {
+		if err := workflow.Sleep(gCtx, d); err != nil {
+			return
+		}
+		w.expired.Send(gCtx, expiry)
+	})
+}
+
+func (w *waitingList) stopTimer(playerID string) {
+	if cancel, ok := w.timers[playerID]; ok {
+		cancel()
+		delete(w.timers, playerID)
+	}
+}
+
+func (w *waitingList) reserveSeat(ctx workflow.Context, tableID string, seat int, playerID string) {
+	req := ReserveSeatRequest{Seat: seat, PlayerID: playerID}
+	err := workflow.SignalExternalWorkflow(ctx, TableWorkflowID(tableID), "", ReserveSeatSignal, req).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to reserve seat", "TableID", tableID, "Seat", seat, "Error", err)
+	}
+}
+
+func (w *waitingList) notify(ctx workflow.Context, playerID string, event poker.TableEvent) {
+	event.PlayerID = playerID
+	err := workflow.ExecuteActivity(ctx, PublishEventActivity, poker.WaitingListSubject(w.list.Key, playerID), event).Get(ctx, nil)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to notify waiting player", "Key", w.list.Key, "PlayerID", playerID, "Error", err)
+	}
+}
+
+// publishPositions tells every player in line where they stand.
+func (w *waitingList) publishPositions(ctx workflow.Context) {
+	for i, entry := range w.list.Entries {
+		w.notify(ctx, entry.PlayerID, poker.TableEvent{
+			Type: "waitingListPosition",
+			Data: map[string]int{"position": i + 1, "waiting": len(w.list.Entries)},
+		})
+	}
+}
@@ -1,72 +1,212 @@
 package temporal
 
 import (
+	"fmt"
+	"hash/fnv"
 	"log"
 	"server/config"
+	"server/internal/codec"
 	"server/internal/db"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/nats-io/nats.go"
+	"go.temporal.io/sdk/activity"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
 	"gorm.io/gorm"
 )
 
+// TaskQueue is the default Temporal task queue. Tournaments, waiting lists and
+// schedules run on it, and so do tables unless shards are configured.
+const TaskQueue = "poker-task-queue"
+
+// DefaultShard names TaskQueue among the shards of config.TemporalConfig.
+const DefaultShard = "default"
+
+// workerStopTimeout is how long a stopping worker lets running activities
+// finish.
+const workerStopTimeout = 30 * time.Second
+
 var (
 	jetStreamInstance nats.JetStreamContext
 	dbInstance        *gorm.DB
+	temporalClient    client.Client
 	once              sync.Once
+	initErr           error
 )
 
-// StartWorker initializes the Temporal worker and required resources.
-func StartWorker(cfg *config.Config) {
+// Workers are the workers of one process, one per task queue it polls.
+type Workers []worker.Worker
+
+// Stop drains every worker at once.
+func (ws Workers) Stop() {
+	var wg sync.WaitGroup
+	for _, w := range ws {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Stop()
+		}()
+	}
+	wg.Wait()
+}
+
+// StartWorker initializes the required resources and starts a Temporal
+// worker for each shard the process serves. Stop the returned workers to
+// drain them. If a worker fails to start, the ones already started are
+// stopped and the error is returned.
+func StartWorker(cfg *config.Config) (Workers, error) {
 	once.Do(func() {
-		// Conectar a NATS y crear JetStream context
-		natsConn, err := nats.Connect(cfg.NATS.Host)
-		if err != nil {
-			log.Fatalf("Failed to connect to NATS: %v", err)
+		initErr = initResources(cfg)
+	})
+	if initErr != nil {
+		return nil, initErr
+	}
+
+	// Crear un cliente Temporal
+	c, err := Dial(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// NOTA: No cerramos la conexión aquí con defer
+	temporalClient = c
+
+	var workers Workers
+	for _, shard := range workerShards(cfg) {
+		w := worker.New(c, ShardTaskQueue(shard), worker.Options{WorkerStopTimeout: workerStopTimeout})
+		RegisterAll(w)
+		if err := w.Start(); err != nil {
+			workers.Stop()
+			return nil, fmt.Errorf("failed to start worker for shard %s: %w", shard, err)
 		}
+		workers = append(workers, w)
+	}
+	return workers, nil
+}
+
+// initResources connects the NATS and database instances shared by the
+// activities.
+func initResources(cfg *config.Config) error {
+	// Conectar a NATS y crear JetStream context
+	natsConn, err := nats.Connect(cfg.NATS.Host)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := natsConn.JetStream()
+	if err != nil {
+		natsConn.Close()
+		return fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+	jetStreamInstance = js
+
+	// Inicializar la base de datos
+	dbConn, err := db.InitDB(&cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	dbInstance = dbConn
+	return nil
+}
+
+// ShardTaskQueue returns the task queue of a shard.
+func ShardTaskQueue(shard string) string {
+	if shard == "" || shard == DefaultShard {
+		return TaskQueue
+	}
+	return TaskQueue + "-" + shard
+}
 
-		js, err := natsConn.JetStream()
-		if err != nil {
-			log.Fatalf("Failed to create JetStream context: %v", err)
+// TableTaskQueue returns the task queue a new table runs on. Tables are
+// spread over the configured shards by ID, so a table always starts on the
+// same shard.
+func TableTaskQueue(cfg *config.Config, tableID string) string {
+	if cfg == nil || len(cfg.Temporal.Shards) == 0 {
+		return TaskQueue
+	}
+	h := fnv.New32a()
+	h.Write([]byte(tableID))
+	return ShardTaskQueue(cfg.Temporal.Shards[h.Sum32()%uint32(len(cfg.Temporal.Shards))])
+}
+
+// IsShard reports whether shard is the default queue or a configured shard.
+func IsShard(cfg *config.Config, shard string) bool {
+	return shard == DefaultShard || slices.Contains(cfg.Temporal.Shards, shard)
+}
+
+func workerShards(cfg *config.Config) []string {
+	if len(cfg.Temporal.WorkerShards) > 0 {
+		return cfg.Temporal.WorkerShards
+	}
+	shards := []string{DefaultShard}
+	for _, shard := range cfg.Temporal.Shards {
+		if shard != DefaultShard {
+			shards = append(shards, shard)
 		}
-		jetStreamInstance = js
+	}
+	return shards
+}
 
-		// Inicializar la base de datos
-		dbConn, err := db.InitDB(&cfg.Database)
-		if err != nil {
-			log.Fatalf("Failed to initialize database: %v", err)
+// Dial connects to Temporal with the data converter that encrypts payloads
+// with the configured codec keys.
+func Dial(cfg *config.Config) (client.Client, error) {
+	dataConverter, err := codec.DataConverter(cfg.Codec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure payload codec: %w", err)
+	}
+	if len(cfg.Codec.Keys) == 0 {
+		if !cfg.DevMode {
+			return nil, fmt.Errorf("no codec key configured: set POKER_CODEC_KEYS, or POKER_DEV_MODE=true to run unencrypted")
 		}
-		dbInstance = dbConn
-	})
+		log.Println("No codec keys configured, Temporal payloads are not encrypted")
+	}
 
-	// Crear un cliente Temporal
 	c, err := client.Dial(client.Options{
-		HostPort: cfg.Temporal.HostPort,
+		HostPort:      cfg.Temporal.HostPort,
+		DataConverter: dataConverter,
 	})
 	if err != nil {
-		log.Fatalf("Failed to create Temporal client: %v", err)
+		return nil, fmt.Errorf("failed to create Temporal client: %w", err)
 	}
-	// NOTA: No cerramos la conexión aquí con defer
+	return c, nil
+}
 
-	// Register Activities and Workflow
-	w := worker.New(c, "poker-task-queue", worker.Options{})
-	w.RegisterWorkflow(TableWorkflow)
-	w.RegisterActivity(DealPreFlop)
-	w.RegisterActivity(DealCardsActivity)
-	w.RegisterActivity(DealFlop)
-	w.RegisterActivity(DealTurn)
-	w.RegisterActivity(DealRiver)
-	w.RegisterActivity(ShowDown)
-	w.RegisterActivity(ShowDownAllFoldExecptOne)
-
-	// Start worker
-	go func() {
-		if err := w.Run(worker.InterruptCh()); err != nil {
-			log.Fatalf("Failed to start worker: %v", err)
-		}
-	}()
+// RegisterAll registers every workflow and activity of the poker server.
+func RegisterAll(r worker.Registry) {
+	r.RegisterWorkflow(TableWorkflow)
+	r.RegisterWorkflow(CashTableWorkflow)
+	r.RegisterWorkflow(WaitingListWorkflow)
+	r.RegisterWorkflow(TournamentClockWorkflow)
+	r.RegisterWorkflow(TournamentWorkflow)
+	r.RegisterWorkflow(TournamentTableWorkflow)
+	r.RegisterWorkflow(SitAndGoWorkflow)
+	r.RegisterWorkflow(TournamentRegistrationWorkflow)
+	r.RegisterWorkflow(TournamentScheduleWorkflow)
+	r.RegisterWorkflow(DealWorkflow)
+	r.RegisterActivity(DealPreFlop)
+	r.RegisterActivity(DealCardsActivity)
+	r.RegisterActivity(DealFlop)
+	r.RegisterActivity(DealTurn)
+	r.RegisterActivity(DealRiver)
+	r.RegisterActivity(ShowDown)
+	r.RegisterActivity(ShowDownAllFoldExecptOne)
+	r.RegisterActivity(VoidHandActivity)
+	r.RegisterActivityWithOptions(LegacyHandleTurnsActivity, activity.RegisterOptions{Name: legacyHandleTurns})
+	r.RegisterActivity(BuyInActivity)
+	r.RegisterActivity(CashOutActivity)
+	r.RegisterActivity(PublishEventActivity)
+	r.RegisterActivity(SendTableUpdateActivity)
+	r.RegisterActivity(RecordRakeActivity)
+	r.RegisterActivity(CurrentBlindLevelActivity)
+	r.RegisterActivity(StartTournamentActivity)
+	r.RegisterActivity(SaveSeatingActivity)
+	r.RegisterActivity(MoveSeatsActivity)
+	r.RegisterActivity(SaveEntryActivity)
+	r.RegisterActivity(CancelTournamentActivity)
+	r.RegisterActivity(RecordEliminationsActivity)
+	r.RegisterActivity(FinishTournamentActivity)
 }
 
 // GetJetStream returns the singleton instance of JetStream
@@ -74,6 +214,11 @@ func GetJetStream() nats.JetStreamContext {
 	return jetStreamInstance
 }
 
+// GetTemporalClient returns the client the worker was started with
+func GetTemporalClient() client.Client {
+	return temporalClient
+}
+
 // GetDB returns the singleton instance of the database
 func GetDB() *gorm.DB {
 	return dbInstance
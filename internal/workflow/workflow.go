@@ -1,113 +1,192 @@
 package temporal
 
 import (
+	"fmt"
 	"server/config"
 	"server/internal/poker"
-	"time"
 
 	"go.temporal.io/sdk/workflow"
 )
 
+// VoidHandSignal cancels the hand in progress on a TableWorkflow and refunds
+// every bet.
+const VoidHandSignal = "VoidHand"
+
+type VoidHandRequest struct {
+	Reason string
+}
+
 func TableWorkflow(ctx workflow.Context, table poker.Table, config *config.Config) (poker.Table, error) {
+	if workflow.GetVersion(ctx, workflowTurnsChange, workflow.DefaultVersion, 1) == workflow.DefaultVersion {
+		return legacyTableWorkflow(ctx, table, config)
+	}
+
 	SecTable := poker.Table{}
-	activityOptions := workflow.ActivityOptions{
-		StartToCloseTimeout: time.Minute * 5,
+	ctx = workflow.WithActivityOptions(ctx, handActivityOptions())
+	voidCh := workflow.GetSignalChannel(ctx, VoidHandSignal)
+	if err := registerTableQueries(ctx, &table, &SecTable); err != nil {
+		return table, err
+	}
+
+	if table.TournamentID != "" {
+		if err := applyBlindLevel(ctx, &table); err != nil {
+			return table, err
+		}
 	}
-	ctx = workflow.WithActivityOptions(ctx, activityOptions)
 
 	err := workflow.ExecuteActivity(ctx, DealPreFlop, &table, config).Get(ctx, &table)
 	if err != nil {
-		return table, err
+		return pauseTable(ctx, table, fmt.Sprintf("failed to start hand: %v", err))
 	}
 
-	if len(table.Players) < 2 { //cambiar por min players de table
+	if len(table.DealtPlayers()) < 2 { //cambiar por min players de table
 		return table, nil
 	}
+	_ = workflow.Sleep(ctx, stagePause)
 
-	err = workflow.ExecuteActivity(ctx, DealCardsActivity, &table, config).Get(ctx, &SecTable)
+	// The deck is shuffled once and kept in the history, so a retried deal
+	// sends the same cards.
+	var deck []poker.Card
+	err = workflow.SideEffect(ctx, func(ctx workflow.Context) interface{} {
+		return poker.ShuffledDeck()
+	}).Get(&deck)
 	if err != nil {
 		return table, err
 	}
+
+	if reason := runHandStep(withHeartbeat(ctx), voidCh, &SecTable, DealCardsActivity, &table, deck, config); reason != "" {
+		return voidHand(ctx, table, reason)
+	}
+	_ = workflow.Sleep(ctx, stagePause)
+	if !SecTable.ValidDeal() {
+		return voidHand(ctx, table, "invalid deal")
+	}
 	table.CurrentStage = "preFlop"
 
-	err = workflow.ExecuteActivity(ctx, HandleTurns, &table).Get(ctx, &table)
-	if err != nil {
-		return table, err
+	if reason := playBettingRound(ctx, voidCh, &table); reason != "" {
+		return voidHand(ctx, table, reason)
 	}
 
 	if table.AllFoldExceptOne {
-		err = workflow.ExecuteActivity(ctx, ShowDownAllFoldExecptOne, &table).Get(ctx, &table)
-		if err != nil {
-			return table, err
+		if reason := runHandStep(ctx, voidCh, &table, ShowDownAllFoldExecptOne, &table); reason != "" {
+			return voidHand(ctx, table, reason)
 		}
-		return table, nil //ver premios
+		return finishHand(ctx, table) //ver premios
 	}
 
 	table.FlopCards = SecTable.FlopCards
 
-	err = workflow.ExecuteActivity(ctx, DealFlop, &table, config).Get(ctx, &table)
-	if err != nil {
-		return table, err
+	if reason := runHandStep(ctx, voidCh, &table, DealFlop, &table, config); reason != "" {
+		return voidHand(ctx, table, reason)
 	}
+	_ = workflow.Sleep(ctx, stagePause)
 
-	err = workflow.ExecuteActivity(ctx, HandleTurns, &table).Get(ctx, &table)
-	if err != nil {
-		return table, err
+	if reason := playBettingRound(ctx, voidCh, &table); reason != "" {
+		return voidHand(ctx, table, reason)
 	}
 
 	if table.AllFoldExceptOne {
-		err = workflow.ExecuteActivity(ctx, ShowDownAllFoldExecptOne, &table).Get(ctx, &table)
-		if err != nil {
-			return table, err
+		if reason := runHandStep(ctx, voidCh, &table, ShowDownAllFoldExecptOne, &table); reason != "" {
+			return voidHand(ctx, table, reason)
 		}
-		return table, nil //ver premios
+		return finishHand(ctx, table) //ver premios
 	}
 
 	table.TurnCard = SecTable.TurnCard
 
-	err = workflow.ExecuteActivity(ctx, DealTurn, &table, config).Get(ctx, &table)
-	if err != nil {
-		return table, err
+	if reason := runHandStep(ctx, voidCh, &table, DealTurn, &table, config); reason != "" {
+		return voidHand(ctx, table, reason)
 	}
+	_ = workflow.Sleep(ctx, stagePause)
 
-	err = workflow.ExecuteActivity(ctx, HandleTurns, &table).Get(ctx, &table)
-	if err != nil {
-		return table, err
+	if reason := playBettingRound(ctx, voidCh, &table); reason != "" {
+		return voidHand(ctx, table, reason)
 	}
 
 	if table.AllFoldExceptOne {
-		err = workflow.ExecuteActivity(ctx, ShowDownAllFoldExecptOne, &table).Get(ctx, &table)
-		if err != nil {
-			return table, err
+		if reason := runHandStep(ctx, voidCh, &table, ShowDownAllFoldExecptOne, &table); reason != "" {
+			return voidHand(ctx, table, reason)
 		}
-		return table, nil //ver premios
+		return finishHand(ctx, table) //ver premios
 	}
 
 	table.RiverCard = SecTable.RiverCard
 
-	err = workflow.ExecuteActivity(ctx, DealRiver, &table, config).Get(ctx, &table)
-	if err != nil {
-		return table, err
+	if reason := runHandStep(ctx, voidCh, &table, DealRiver, &table, config); reason != "" {
+		return voidHand(ctx, table, reason)
 	}
+	_ = workflow.Sleep(ctx, stagePause)
 
-	err = workflow.ExecuteActivity(ctx, HandleTurns, &table).Get(ctx, &table)
-	if err != nil {
-		return table, err
+	if reason := playBettingRound(ctx, voidCh, &table); reason != "" {
+		return voidHand(ctx, table, reason)
 	}
 
 	if table.AllFoldExceptOne {
-		err = workflow.ExecuteActivity(ctx, ShowDownAllFoldExecptOne, &table).Get(ctx, &table)
-		if err != nil {
-			return table, err
+		if reason := runHandStep(ctx, voidCh, &table, ShowDownAllFoldExecptOne, &table); reason != "" {
+			return voidHand(ctx, table, reason)
 		}
-		return table, nil //ver premios
+		return finishHand(ctx, table) //ver premios
 	}
 
 	table.AssignPlayerCardsFromSecTable(&SecTable)
 
-	err = workflow.ExecuteActivity(ctx, ShowDown, &table).Get(ctx, &table)
+	if reason := runHandStep(ctx, voidCh, &table, ShowDown, &table); reason != "" {
+		return voidHand(ctx, table, reason)
+	}
+
+	return finishHand(ctx, table)
+}
+
+// finishHand records the accounting of a settled hand.
+func finishHand(ctx workflow.Context, table poker.Table) (poker.Table, error) {
+	if table.Rake > 0 {
+		ctx = workflow.WithActivityOptions(ctx, durableActivityOptions())
+		err := workflow.ExecuteActivity(ctx, RecordRakeActivity, table.ID, table.HandNumber, table.Pots).Get(ctx, nil)
+		if err != nil {
+			// The hand is settled; only the accounting is missing.
+			alertAdmins(ctx, table, fmt.Sprintf("failed to record rake of hand %d: %v", table.HandNumber, err))
+		}
+	}
+
+	return table, nil
+}
+
+// runHandStep executes one activity of a hand in progress. It returns a
+// non-empty reason when the hand has to be voided, either because the
+// activity failed or because a VoidHandSignal arrived while it was running.
+func runHandStep(ctx workflow.Context, voidCh workflow.ReceiveChannel, result interface{}, activity interface{}, args ...interface{}) string {
+	stepCtx, cancel := workflow.WithCancel(ctx)
+	defer cancel()
+
+	var reason string
+	selector := workflow.NewSelector(ctx)
+	selector.AddFuture(workflow.ExecuteActivity(stepCtx, activity, args...), func(f workflow.Future) {
+		if err := f.Get(ctx, result); err != nil {
+			reason = fmt.Sprintf("hand step failed: %v", err)
+		}
+	})
+	selector.AddReceive(voidCh, func(c workflow.ReceiveChannel, more bool) {
+		var req VoidHandRequest
+		c.Receive(ctx, &req)
+		reason = req.Reason
+		if reason == "" {
+			reason = "voided by admin"
+		}
+	})
+	selector.Select(ctx)
+
+	return reason
+}
+
+func voidHand(ctx workflow.Context, table poker.Table, reason string) (poker.Table, error) {
+	workflow.GetLogger(ctx).Warn("Voiding hand", "TableID", table.ID, "Reason", reason)
+
+	err := workflow.ExecuteActivity(ctx, VoidHandActivity, &table, reason).Get(ctx, &table)
 	if err != nil {
-		return table, err
+		// Refund the bets anyway so no chips are lost, but stop the table
+		// until someone looks at it.
+		table.VoidHand(reason)
+		return pauseTable(ctx, table, fmt.Sprintf("failed to void hand: %v", err))
 	}
 
 	return table, nil
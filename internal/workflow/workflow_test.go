@@ -20,7 +20,11 @@ func TestTableWorkflow(t *testing.T) {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	StartWorker(cfg)
+	workers, err := StartWorker(cfg)
+	if err != nil {
+		log.Fatalf("Failed to start workers: %v", err)
+	}
+	defer workers.Stop()
 
 	temporalOptions := client.Options{
 		HostPort: cfg.Temporal.HostPort,
@@ -49,15 +53,7 @@ func TestTableWorkflow(t *testing.T) {
 	})
 	workerOptions := worker.Options{}
 	w := worker.New(c, "poker-task-queue", workerOptions)
-	w.RegisterWorkflow(TableWorkflow)
-	w.RegisterActivity(DealPreFlop)
-	w.RegisterActivity(DealCardsActivity)
-	w.RegisterActivity(DealFlop)
-	w.RegisterActivity(DealTurn)
-	w.RegisterActivity(DealRiver)
-	w.RegisterActivity(HandleTurns)
-	w.RegisterActivity(ShowDown)
-	w.RegisterActivity(ShowDownAllFoldExecptOne)
+	RegisterAll(w)
 	// Start worker
 	go func() {
 		if err := w.Run(worker.InterruptCh()); err != nil {
@@ -69,19 +65,16 @@ func TestTableWorkflow(t *testing.T) {
 	time.Sleep(2 * time.Second) // Adjust sleep duration if needed
 
 	// Define test tables
-	table1 := poker.Table{
-		ID:                 "1",
-		BBValue:            100,
-		TurnTime:           5,
-		FlopCards:          []poker.Card{},
-		TurnCard:           nil,
-		RiverCard:          nil,
-		TotalBetIndividual: make(map[string]int),
-		Players: []poker.Player{
-			{ID: "player1", Chips: 1000},
-			{ID: "player2", Chips: 1000},
-			{ID: "player3", Chips: 1000},
-		},
+	table1, err := poker.NewTable("1", 9)
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	table1.BBValue = 100
+	table1.TurnTime = 5
+	for i, id := range []string{"player1", "player2", "player3"} {
+		if err := table1.SitPlayer(i+1, poker.Player{ID: id, Chips: 1000}); err != nil {
+			t.Fatalf("Failed to seat %s: %v", id, err)
+		}
 	}
 
 	// Start workflows
@@ -107,7 +100,7 @@ func TestTableWorkflow(t *testing.T) {
 
 	workflowID2 := "table-workflow-782"
 
-	for len(table1.Players) >= 2 {
+	for table1.PlayerCount() >= 2 {
 		we2, err := c.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{
 			ID:        workflowID2,
 			TaskQueue: "poker-task-queue",
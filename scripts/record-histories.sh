@@ -0,0 +1,111 @@
+#!/bin/sh
+# Records the histories replayed by TestReplayTableHistories from a real
+# Temporal server. It starts `temporal server start-dev`, runs the worker of a
+# revision (by default the baseline running in production) against it, plays
+# a few hands and exports them with `temporal workflow show --output json` to
+# internal/workflow/testdata/histories.
+#
+# Needs the temporal CLI on PATH and the postgres and nats services of
+# docker-compose.yml running.
+#
+#   scripts/record-histories.sh [revision]
+set -eu
+
+rev=${1:-d9092ac}
+root=$(git rev-parse --show-toplevel)
+out="$root/internal/workflow/testdata/histories"
+tree=$(mktemp -d)
+address=localhost:7244
+pids=""
+
+cleanup() {
+	for pid in $pids; do
+		kill "$pid" 2>/dev/null || true
+	done
+	git -C "$root" worktree remove --force "$tree"
+}
+
+git -C "$root" worktree add --quiet --detach "$tree" "$rev"
+trap cleanup EXIT
+
+temporal server start-dev --headless --port 7244 --ui-port 8244 --log-level error &
+pids="$pids $!"
+until temporal operator cluster health --address "$address" >/dev/null 2>&1; do
+	sleep 1
+done
+
+# The worker of the revision, as its StartWorker runs it, with the stream its
+# activities publish to.
+mkdir -p "$tree/cmd/record-worker"
+cat >"$tree/cmd/record-worker/main.go" <<'EOF'
+package main
+
+import (
+	"log"
+	"server/config"
+	temporal "server/internal/workflow"
+
+	"github.com/nats-io/nats.go"
+	"go.temporal.io/sdk/worker"
+)
+
+func main() {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	nc, err := nats.Connect(cfg.NATS.Host)
+	if err != nil {
+		log.Fatalf("Failed to connect to NATS: %v", err)
+	}
+	js, err := nc.JetStream()
+	if err != nil {
+		log.Fatalf("Failed to create JetStream context: %v", err)
+	}
+	_, err = js.AddStream(&nats.StreamConfig{Name: "POKER_TOURNAMENT", Subjects: []string{"pokerServer.tournament.>"}})
+	if err != nil {
+		log.Printf("Using the existing stream: %v", err)
+	}
+	temporal.StartWorker(cfg)
+	<-worker.InterruptCh()
+}
+EOF
+cat >"$tree/config.yaml" <<EOF
+database:
+  host: localhost
+  port: 5432
+  user: postgres
+  password: popio
+  dbname: poker
+  sslmode: disable
+nats:
+  host: nats://localhost:4222
+temporal:
+  hostport: $address
+EOF
+(cd "$tree" && go build -o record-worker ./cmd/record-worker)
+(cd "$tree" && exec ./record-worker) &
+pids="$pids $!"
+
+export TEMPORAL_ADDRESS="$address"
+
+# A table left with one player ends after DealPreFlop.
+temporal workflow execute --task-queue poker-task-queue --type TableWorkflow \
+	--workflow-id replay-not-enough-players \
+	--input '{"ID":"1","BBValue":100,"TurnTime":5,"FlopCards":[],"TotalBetIndividual":{},"Players":[{"ID":"player1","Chips":1000}]}' \
+	--input 'null' >/dev/null
+
+# A hand with players is dealt and then waits on HandleTurns, which the
+# workers of the revision never registered, as hands in production do.
+temporal workflow start --task-queue poker-task-queue --type TableWorkflow \
+	--workflow-id replay-waiting-for-turns \
+	--input '{"ID":"2","BBValue":100,"TurnTime":5,"FlopCards":[],"TotalBetIndividual":{},"Players":[{"ID":"player1","Chips":1000},{"ID":"player2","Chips":1000},{"ID":"player3","Chips":1000}]}' \
+	--input 'null' >/dev/null
+until temporal workflow describe --workflow-id replay-waiting-for-turns | grep -q HandleTurns; do
+	sleep 1
+done
+
+mkdir -p "$out"
+temporal workflow show --workflow-id replay-not-enough-players --output json >"$out/table_not_enough_players.json"
+temporal workflow show --workflow-id replay-waiting-for-turns --output json >"$out/table_waiting_for_turns.json"
+temporal workflow terminate --workflow-id replay-waiting-for-turns --reason "history recorded" >/dev/null